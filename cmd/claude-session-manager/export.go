@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"claude-session-manager/internal/config"
+	"claude-session-manager/internal/export"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportOutput string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <session>",
+	Short: "Write a session's transcript as Markdown, HTML or JSON",
+	Long: `Export renders a session's transcript with roles, timestamps, code blocks and
+usage. The session is an ID, an unambiguous ID prefix or @alias of any open or
+archived session. The format defaults to the output file's extension, or
+Markdown when writing to stdout.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := export.Markdown
+		var err error
+		switch {
+		case exportFormat != "":
+			format, err = export.ParseFormat(exportFormat)
+		case exportOutput != "":
+			format, err = export.FormatForPath(exportOutput)
+		}
+		if err != nil {
+			return err
+		}
+
+		st, err := openStore()
+		if err != nil {
+			return err
+		}
+		journals, err := config.DataPath("journals", "")
+		if err != nil {
+			return err
+		}
+		if err := st.IndexJournals(journals); err != nil {
+			return err
+		}
+		state, err := st.Resolve(args[0])
+		if err != nil {
+			return err
+		}
+
+		if exportOutput == "" {
+			return export.Write(os.Stdout, state, format)
+		}
+		file, err := os.Create(exportOutput)
+		if err != nil {
+			return err
+		}
+		if err := export.Write(file, state, format); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Exported %s to %s\n", state.Name, exportOutput)
+		return nil
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "", "md, html or json")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "file to write instead of stdout")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"claude-session-manager/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var importList bool
+
+var importCmd = &cobra.Command{
+	Use:   "import [log.jsonl]...",
+	Short: "Open Claude Code conversations from ~/.claude/projects in the TUI",
+	Long: `Import reads Claude Code conversation logs, every one under ~/.claude/projects
+unless files are given, and opens them as stopped sessions. Adopting one in the
+TUI (A) continues the conversation through the claude CLI with --resume.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			dir, err := session.ClaudeProjectsDir()
+			if err != nil {
+				return err
+			}
+			if args, err = session.FindClaudeLogs(dir); err != nil {
+				return err
+			}
+		}
+
+		if importList {
+			for _, path := range args {
+				state, err := session.ReadClaudeLog(path)
+				if err != nil {
+					continue
+				}
+				fmt.Printf("%s\t%s\t%d entries\t%s\n", state.Config.ResumeID, state.UpdatedAt.Format("2006-01-02 15:04"), len(state.Transcript), state.Name)
+			}
+			return nil
+		}
+
+		manager, err := newManager()
+		if err != nil {
+			return err
+		}
+		for _, path := range args {
+			if _, err := manager.ImportClaudeLog(path); err != nil && !errors.Is(err, session.ErrEmptyClaudeLog) {
+				fmt.Fprintf(os.Stderr, "skipping %s: %v\n", path, err)
+			}
+		}
+		return runTUI(manager)
+	},
+}
+
+func init() {
+	importCmd.Flags().BoolVar(&importList, "list", false, "list the conversations instead of opening them")
+	rootCmd.AddCommand(importCmd)
+}
@@ -1,9 +1,16 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
+	"claude-session-manager/internal/config"
+	"claude-session-manager/internal/control"
+	"claude-session-manager/internal/session"
+	"claude-session-manager/internal/store"
 	"claude-session-manager/internal/tui"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
@@ -16,6 +23,8 @@ var rootCmd = &cobra.Command{
 multiple Claude AI sessions simultaneously. It enables developers to spawn, 
 monitor, and facilitate complex interactions between multiple AI sessions 
 from a single terminal interface.`,
+	// main prints the error once, whichever command failed.
+	SilenceErrors: true,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := startTUI(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error starting TUI: %v\n", err)
@@ -32,20 +41,191 @@ var versionCmd = &cobra.Command{
 	},
 }
 
+var (
+	backendName  string
+	claudePath   string
+	model        string
+	systemPrompt string
+	maxTokens    int
+	temperature  float64
+	pricesPath   string
+
+	maxAttempts int
+	noJournal   bool
+
+	globalBudget  session.Budget
+	sessionBudget session.Budget
+
+	// sessionStore archives and indexes sessions; newManager opens it.
+	sessionStore *store.Store
+)
+
 func init() {
+	rootCmd.PersistentFlags().StringVar(&backendName, "backend", "fake", "session backend: fake, cli or api (the session commands default to cli)")
+	rootCmd.PersistentFlags().StringVar(&claudePath, "claude-path", "claude", "path to the claude CLI used by the cli backend")
+	rootCmd.PersistentFlags().StringVar(&model, "model", session.DefaultModel, "model for new sessions")
+	rootCmd.PersistentFlags().StringVar(&systemPrompt, "system-prompt", "", "system prompt for new sessions")
+	rootCmd.PersistentFlags().IntVar(&maxTokens, "max-tokens", session.DefaultMaxTokens, "max_tokens for new sessions")
+	rootCmd.PersistentFlags().Float64Var(&temperature, "temperature", -1, "sampling temperature for new sessions (negative uses the API default)")
+	rootCmd.PersistentFlags().StringVar(&pricesPath, "prices", "", "JSON file of per-model prices (USD per million tokens) merged over the defaults")
+	flags := rootCmd.PersistentFlags()
+	flags.Float64Var(&globalBudget.SoftCost, "budget-soft", 0, "warn once all sessions together have cost this many USD")
+	flags.Float64Var(&globalBudget.HardCost, "budget-hard", 0, "stop all sessions once together they have cost this many USD")
+	flags.IntVar(&globalBudget.SoftTokens, "budget-soft-tokens", 0, "warn once all sessions together have used this many tokens")
+	flags.IntVar(&globalBudget.HardTokens, "budget-hard-tokens", 0, "stop all sessions once together they have used this many tokens")
+	flags.Float64Var(&sessionBudget.SoftCost, "session-budget-soft", 0, "warn once a single session has cost this many USD")
+	flags.Float64Var(&sessionBudget.HardCost, "session-budget-hard", 0, "stop a session once it has cost this many USD")
+	flags.IntVar(&maxAttempts, "max-attempts", session.DefaultRetryPolicy().MaxAttempts, "attempts per request before a transient error is reported")
+	flags.BoolVar(&noJournal, "no-journal", false, "do not journal sessions to the data directory for crash recovery")
 	rootCmd.AddCommand(versionCmd)
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
 
+func newManager() (*session.Manager, error) {
+	manager := session.NewManager()
+
+	sessionConfig := session.SessionConfig{
+		Model:        model,
+		SystemPrompt: systemPrompt,
+		MaxTokens:    maxTokens,
+	}
+	if temperature >= 0 {
+		sessionConfig.Temperature = &temperature
+	}
+	sessionConfig.Budget = sessionBudget
+	manager.SetDefaultConfig(sessionConfig)
+	manager.SetBudget(globalBudget)
+
+	retry := session.DefaultRetryPolicy()
+	retry.MaxAttempts = maxAttempts
+	manager.SetRetryPolicy(retry)
+
+	if pricesPath != "" {
+		prices, err := session.LoadPriceTable(pricesPath)
+		if err != nil {
+			return nil, err
+		}
+		manager.SetPriceTable(prices)
+	}
+
+	if !noJournal {
+		dir, err := config.DataPath("journals", "")
+		if err != nil {
+			return nil, err
+		}
+		if err := manager.SetJournalDir(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	st, err := openStore()
+	if err != nil {
+		return nil, err
+	}
+	sessionStore = st
+	manager.SetArchiver(st)
+
+	var factory session.BackendFactory
+	switch backendName {
+	case "fake":
+		factory = func(*session.Session) session.Backend {
+			backend := session.NewFakeBackend()
+			backend.Delay = 40 * time.Millisecond
+			return backend
+		}
+	case "cli":
+		factory = func(*session.Session) session.Backend {
+			return session.NewCLIBackend(claudePath)
+		}
+	case "api":
+		apiKey := os.Getenv("ANTHROPIC_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("the api backend requires ANTHROPIC_API_KEY")
+		}
+		baseURL := os.Getenv("ANTHROPIC_BASE_URL")
+		factory = func(*session.Session) session.Backend {
+			backend := session.NewAPIBackend(apiKey)
+			if baseURL != "" {
+				backend.BaseURL = baseURL
+			}
+			return backend
+		}
+	default:
+		return nil, fmt.Errorf("unknown backend %q", backendName)
+	}
+
+	// Conversations adopted from Claude Code can only be continued by the
+	// CLI that owns them, whatever backend new sessions use.
+	manager.SetBackendFactory(func(s *session.Session) session.Backend {
+		if s.Config.ResumeID == "" {
+			return factory(s)
+		}
+		backend := session.NewCLIBackend(claudePath)
+		backend.Dir = s.Config.WorkDir
+		backend.Resume(s.Config.ResumeID)
+		return backend
+	})
+	return manager, nil
+}
+
 func startTUI() error {
-	model := tui.NewModel()
+	manager, err := newManager()
+	if err != nil {
+		return err
+	}
+	return runTUI(manager)
+}
+
+func runTUI(manager *session.Manager) error {
+	return runModel(manager, tui.NewModel(manager))
+}
+
+// runModel runs the TUI over model and closes the manager when it exits.
+// While it runs, the session commands work on its sessions.
+func runModel(manager *session.Manager, model *tui.Model) error {
+	// A second ClaudePilot leaves the commands to the first one.
+	if path, err := controlSocket(); err == nil {
+		if server, err := control.Serve(path, manager, sessionStore); err == nil {
+			defer server.Close()
+		}
+	}
+	if sessionStore != nil {
+		model.SetStore(sessionStore)
+	}
+	if dir, err := config.ConfigDir(); err == nil {
+		model.LoadScripts(filepath.Join(dir, "scripts"))
+		model.SetTemplateDir(filepath.Join(dir, "templates"))
+	}
 	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	_, err := p.Run()
+	if closeErr := manager.Close(); err == nil {
+		err = closeErr
+	}
 	return err
 }
+
+// openStore opens the session archive in the data directory.
+func openStore() (*store.Store, error) {
+	dir, err := config.DataPath("sessions", "")
+	if err != nil {
+		return nil, err
+	}
+	st, err := store.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, err := range st.Skipped() {
+		fmt.Fprintf(os.Stderr, "skipping %v\n", err)
+	}
+	return st, nil
+}
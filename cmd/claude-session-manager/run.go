@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"claude-session-manager/internal/tui"
+	"claude-session-manager/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runHeadless bool
+	runTimeout  time.Duration
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <workflow.yaml>",
+	Short: "Run a workflow of sessions connected by pipes",
+	Long: `Run creates the sessions a workflow file declares and sends each its prompt
+once the sessions feeding it have replied. The graph runs in the TUI, or with
+--headless prints progress to stderr and the final replies to stdout, exiting
+non-zero if any session fails.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// A failed run is not a usage mistake.
+		cmd.SilenceUsage = true
+
+		wf, err := workflow.Load(args[0])
+		if err != nil {
+			return err
+		}
+		manager, err := newManager()
+		if err != nil {
+			return err
+		}
+
+		if !runHeadless {
+			return runModel(manager, tui.NewWorkflowModel(manager, wf))
+		}
+
+		ctx := context.Background()
+		if runTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, runTimeout)
+			defer cancel()
+		}
+		run := workflow.Start(ctx, manager, wf, func(status workflow.NodeStatus) {
+			if status.Detail != "" {
+				fmt.Fprintf(os.Stderr, "[%s] %s: %s\n", status.ID, status.State, status.Detail)
+			} else {
+				fmt.Fprintf(os.Stderr, "[%s] %s\n", status.ID, status.State)
+			}
+		})
+		runErr := run.Wait()
+
+		outputs := run.Outputs()
+		for _, output := range outputs {
+			if len(outputs) > 1 {
+				fmt.Printf("## %s\n\n", output.ID)
+			}
+			fmt.Println(output.Text)
+		}
+		if err := manager.Close(); runErr == nil {
+			runErr = err
+		}
+		return runErr
+	},
+}
+
+func init() {
+	runCmd.Flags().BoolVar(&runHeadless, "headless", false, "run without the TUI")
+	runCmd.Flags().DurationVar(&runTimeout, "timeout", 0, "give up on a headless run after this long (0 waits forever)")
+	rootCmd.AddCommand(runCmd)
+}
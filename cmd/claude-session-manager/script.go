@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"claude-session-manager/internal/script"
+	"github.com/spf13/cobra"
+)
+
+var scriptTimeout time.Duration
+
+var scriptCmd = &cobra.Command{
+	Use:   "script <file.lua> [args...]",
+	Short: "Run a Lua script that drives sessions",
+	Long: `Script runs a Lua file against a fresh session manager. The script can create
+sessions, send them prompts, wait for their replies and branch or loop on what
+they say. Arguments after the file are available to it as args; what it prints
+goes to stdout. The exit status is non-zero if the script raises an error.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		s, err := script.Load(args[0])
+		if err != nil {
+			return err
+		}
+		manager, err := newManager()
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		if scriptTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, scriptTimeout)
+			defer cancel()
+		}
+		runErr := s.Run(ctx, script.Env{Manager: manager, Args: args[1:], Output: os.Stdout})
+		if err := manager.Close(); runErr == nil {
+			runErr = err
+		}
+		return runErr
+	},
+}
+
+func init() {
+	// Flags after the script file belong to the script.
+	scriptCmd.Flags().SetInterspersed(false)
+	scriptCmd.Flags().DurationVar(&scriptTimeout, "timeout", 0, "give up on the script after this long (0 waits forever)")
+	rootCmd.AddCommand(scriptCmd)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"claude-session-manager/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var searchLimit int
+
+var searchCmd = &cobra.Command{
+	Use:   "search <phrase>...",
+	Short: "Search the transcripts of all sessions, open and archived",
+	Long: `Search prints every output line containing the phrase, ignoring case, as
+<session>:<line>. Sessions open in a running ClaudePilot are searched through
+their journals.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		st, err := openStore()
+		if err != nil {
+			return err
+		}
+		journals, err := config.DataPath("journals", "")
+		if err != nil {
+			return err
+		}
+		if err := st.IndexJournals(journals); err != nil {
+			return err
+		}
+
+		hits := st.Search(strings.Join(args, " "), searchLimit)
+		if len(hits) == 0 {
+			return fmt.Errorf("no matches for %q", strings.Join(args, " "))
+		}
+		for _, hit := range hits {
+			name := hit.Name
+			if hit.Alias != "" {
+				name += " @" + hit.Alias
+			}
+			fmt.Printf("%s %s:%d\t%s\n", hit.SessionID, name, hit.Line+1, strings.TrimSpace(hit.Text))
+		}
+		return nil
+	},
+}
+
+func init() {
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 50, "maximum number of matches to print (0 for all)")
+	rootCmd.AddCommand(searchCmd)
+}
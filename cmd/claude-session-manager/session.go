@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"claude-session-manager/internal/config"
+	"claude-session-manager/internal/control"
+	"claude-session-manager/internal/session"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of the session commands, beyond 1 for anything else.
+const (
+	exitNotFound  = 3
+	exitBusy      = 4
+	exitStopped   = 5
+	exitRequest   = 6
+	exitTimeout   = 124
+	exitCancelled = 130
+)
+
+// exitError makes main exit with code instead of 1.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode gives err the exit code for what went wrong.
+func withExitCode(err error) error {
+	if err == nil {
+		return nil
+	}
+	code := 1
+	switch control.Kind(err) {
+	case control.KindNotFound:
+		code = exitNotFound
+	case control.KindBusy:
+		code = exitBusy
+	case control.KindStopped:
+		code = exitStopped
+	case control.KindRequest:
+		code = exitRequest
+	case control.KindTimeout:
+		code = exitTimeout
+	case control.KindCancelled:
+		code = exitCancelled
+	}
+	return &exitError{code: code, err: err}
+}
+
+var (
+	sessionJSON    bool
+	sessionAlias   string
+	sessionTimeout time.Duration
+	sessionLines   int
+)
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Create, list, prompt and kill sessions from the shell",
+	Long: `The session commands work on the sessions of the ClaudePilot running on this
+machine, which shows their effects live. When none is running they work on the
+session archive instead, so sessions carry over from one command to the next,
+and prompts go to the claude CLI unless --backend names another backend.
+
+Sessions are named by ID, unambiguous ID prefix or @alias. Exit codes: 0 on
+success, 3 if the session is not found, 4 if it is busy, 5 if it is stopped,
+killed or over budget, 6 if the request failed, 124 on --timeout, 130 if
+interrupted and 1 for anything else.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Failures are reported through the exit code, not usage text.
+		cmd.SilenceUsage = true
+	},
+}
+
+var sessionCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a session and print its ID",
+	Long: `Create makes a new session. --model, --system-prompt, --max-tokens and
+--temperature, when given, override the defaults of the ClaudePilot it is
+created in.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := control.CreateOptions{Alias: sessionAlias}
+		if len(args) > 0 {
+			opts.Name = args[0]
+		}
+		flags := cmd.Flags()
+		if flags.Changed("model") {
+			opts.Model = model
+		}
+		if flags.Changed("system-prompt") {
+			opts.SystemPrompt = systemPrompt
+		}
+		if flags.Changed("max-tokens") {
+			opts.MaxTokens = maxTokens
+		}
+		if flags.Changed("temperature") && temperature >= 0 {
+			opts.Temperature = &temperature
+		}
+
+		return withSessions(func(sessions control.Sessions) error {
+			info, err := sessions.Create(opts)
+			if err != nil {
+				return err
+			}
+			if sessionJSON {
+				return printJSON(info)
+			}
+			fmt.Println(info.ID)
+			return nil
+		})
+	},
+}
+
+var sessionListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the sessions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withSessions(func(sessions control.Sessions) error {
+			infos, err := sessions.List()
+			if err != nil {
+				return err
+			}
+			if sessionJSON {
+				if infos == nil {
+					infos = []control.Info{}
+				}
+				return printJSON(infos)
+			}
+			for _, info := range infos {
+				fmt.Printf("%s\t%s\t%s\t%d messages\t$%.4f\n", info.ID, displayName(info), info.Status, info.Messages, info.Cost)
+			}
+			return nil
+		})
+	},
+}
+
+var sessionStatusCmd = &cobra.Command{
+	Use:   "status <session>",
+	Short: "Print a session's status, model and usage",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withSessions(func(sessions control.Sessions) error {
+			info, err := sessions.Status(args[0])
+			if err != nil {
+				return err
+			}
+			if sessionJSON {
+				return printJSON(info)
+			}
+			printInfo(info)
+			return nil
+		})
+	},
+}
+
+var sessionSendCmd = &cobra.Command{
+	Use:   "send <session> [prompt...]",
+	Short: "Send a prompt and stream the reply to stdout",
+	Long: `Send sends the prompt, or stdin when no prompt is given or it is "-", and
+streams the reply to stdout as it arrives. With --json each piece of the reply
+is a {"text": ...} line, followed by a final {"session": ..., "reply": ...}.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prompt := strings.Join(args[1:], " ")
+		if prompt == "" || prompt == "-" {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+			prompt = string(data)
+		}
+		if strings.TrimSpace(prompt) == "" {
+			return errors.New("empty prompt")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		if sessionTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, sessionTimeout)
+			defer cancel()
+		}
+
+		return withSessions(func(sessions control.Sessions) error {
+			encoder := json.NewEncoder(os.Stdout)
+			streamed := false
+			result, err := sessions.Send(ctx, args[0], prompt, func(text string) {
+				streamed = true
+				if sessionJSON {
+					encoder.Encode(struct {
+						Text string `json:"text"`
+					}{text})
+				} else {
+					fmt.Print(text)
+				}
+			})
+			if !sessionJSON && streamed && !strings.HasSuffix(result.Reply, "\n") {
+				fmt.Println()
+			}
+			if err != nil {
+				return err
+			}
+			if sessionJSON {
+				return encoder.Encode(result)
+			}
+			return nil
+		})
+	},
+}
+
+var sessionKillCmd = &cobra.Command{
+	Use:   "kill <session>",
+	Short: "Kill a session for good",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withSessions(func(sessions control.Sessions) error {
+			info, err := sessions.Status(args[0])
+			if err != nil {
+				return err
+			}
+			if err := sessions.Kill(info.ID); err != nil {
+				return err
+			}
+			info.Status = session.StatusKilled.String()
+			if sessionJSON {
+				return printJSON(info)
+			}
+			fmt.Printf("Killed %s (%s)\n", displayName(info), info.ID)
+			return nil
+		})
+	},
+}
+
+var sessionLogsCmd = &cobra.Command{
+	Use:   "logs <session>",
+	Short: "Print a session's transcript",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withSessions(func(sessions control.Sessions) error {
+			entries, err := sessions.Logs(args[0], sessionLines)
+			if err != nil {
+				return err
+			}
+			if sessionJSON {
+				if entries == nil {
+					entries = []session.Entry{}
+				}
+				return printJSON(entries)
+			}
+			for _, line := range session.RenderLines(entries) {
+				fmt.Println(line)
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	sessionCmd.PersistentFlags().BoolVar(&sessionJSON, "json", false, "print JSON instead of text")
+	sessionCreateCmd.Flags().StringVar(&sessionAlias, "alias", "", "alias to refer to the session by")
+	sessionSendCmd.Flags().DurationVar(&sessionTimeout, "timeout", 0, "cancel the request after this long (0 waits forever)")
+	sessionLogsCmd.Flags().IntVarP(&sessionLines, "lines", "n", 0, "print only the last N transcript entries")
+	sessionCmd.AddCommand(sessionCreateCmd, sessionListCmd, sessionStatusCmd, sessionSendCmd, sessionKillCmd, sessionLogsCmd)
+	rootCmd.AddCommand(sessionCmd)
+}
+
+// controlSocket is where a running ClaudePilot listens for session commands.
+func controlSocket() (string, error) {
+	return config.DataPath("control.sock")
+}
+
+// withSessions runs fn against the running ClaudePilot or, when there is
+// none, a manager over the archive that is closed afterwards. Errors get the
+// exit code describing them.
+func withSessions(fn func(control.Sessions) error) error {
+	path, err := controlSocket()
+	if err != nil {
+		return err
+	}
+	if client, err := control.Dial(path); err == nil {
+		return withExitCode(fn(client))
+	}
+
+	// Prompts sent from the shell are real work, so they go to the claude
+	// CLI unless --backend asks for another backend; the fake one is a demo.
+	if !rootCmd.PersistentFlags().Changed("backend") {
+		backendName = "cli"
+	}
+	manager, err := newManager()
+	if err != nil {
+		return err
+	}
+	err = fn(&control.Local{Manager: manager, Store: sessionStore})
+	if closeErr := manager.Close(); err == nil {
+		err = closeErr
+	}
+	return withExitCode(err)
+}
+
+func displayName(info control.Info) string {
+	if info.Alias == "" {
+		return info.Name
+	}
+	return info.Name + " @" + info.Alias
+}
+
+func printInfo(info control.Info) {
+	fmt.Printf("id:       %s\n", info.ID)
+	fmt.Printf("name:     %s\n", displayName(info))
+	status := info.Status
+	if info.StopReason != "" {
+		status += " (" + info.StopReason + ")"
+	}
+	if !info.Open {
+		status += ", archived"
+	}
+	fmt.Printf("status:   %s\n", status)
+	if info.LastError != "" {
+		fmt.Printf("error:    %s\n", info.LastError)
+	}
+	fmt.Printf("model:    %s\n", info.Model)
+	fmt.Printf("messages: %d\n", info.Messages)
+	fmt.Printf("usage:    %d in, %d out, $%.4f\n", info.Usage.InputTokens, info.Usage.OutputTokens, info.Cost)
+	fmt.Printf("updated:  %s\n", info.UpdatedAt.Local().Format("2006-01-02 15:04:05"))
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"claude-session-manager/internal/control"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// resetFlags puts every flag of cmd and its subcommands back to its default,
+// since the commands and their flags outlive one run.
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		f.Value.Set(f.DefValue)
+		f.Changed = false
+	}
+	cmd.PersistentFlags().VisitAll(reset)
+	cmd.Flags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// runCLI runs the command line args and returns what it printed to stdout.
+func runCLI(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	resetFlags(rootCmd)
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	printed := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		printed <- string(data)
+	}()
+
+	rootCmd.SetArgs(args)
+	err = rootCmd.Execute()
+	w.Close()
+	os.Stdout = stdout
+	return <-printed, err
+}
+
+func TestSessionCommandsJSON(t *testing.T) {
+	t.Setenv("CLAUDEPILOT_DATA_DIR", t.TempDir())
+
+	out, err := runCLI(t, "session", "create", "reviewer", "--alias", "rev", "--json", "--backend", "fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created control.Info
+	if err := json.Unmarshal([]byte(out), &created); err != nil || created.ID == "" || created.Alias != "rev" {
+		t.Fatalf("create printed %q (%v)", out, err)
+	}
+
+	out, err = runCLI(t, "session", "send", "@rev", "hello", "--json", "--backend", "fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One line per streamed piece, then the result.
+	var (
+		streamed strings.Builder
+		result   control.SendResult
+	)
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		var line map[string]json.RawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("send printed %q", scanner.Text())
+		}
+		if text, ok := line["text"]; ok {
+			var piece string
+			json.Unmarshal(text, &piece)
+			streamed.WriteString(piece)
+			continue
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if result.Reply != "Claude response to: hello" || streamed.String() != result.Reply || result.Session.ID != created.ID {
+		t.Errorf("streamed %q, result %+v", streamed.String(), result)
+	}
+
+	// The archive carries the session over to the next command.
+	out, err = runCLI(t, "session", "list", "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var infos []control.Info
+	if err := json.Unmarshal([]byte(out), &infos); err != nil || len(infos) != 1 || infos[0].Messages != 2 || infos[0].Open {
+		t.Errorf("list printed %q (%v)", out, err)
+	}
+}
+
+func TestSessionSendUsesCLIByDefault(t *testing.T) {
+	t.Setenv("CLAUDEPILOT_DATA_DIR", t.TempDir())
+	claude, err := filepath.Abs(filepath.Join("..", "..", "internal", "session", "testdata", "fake-claude"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runCLI(t, "session", "create", "--claude-path", claude)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err = runCLI(t, "session", "send", strings.TrimSpace(out), "hello", "--claude-path", claude)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "Listing files.Done.\n" {
+		t.Errorf("send printed %q, want the claude CLI's reply", out)
+	}
+}
+
+func TestSessionExitCodes(t *testing.T) {
+	t.Setenv("CLAUDEPILOT_DATA_DIR", t.TempDir())
+	_, err := runCLI(t, "session", "status", "nope")
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) || exitErr.code != exitNotFound {
+		t.Fatalf("status of an unknown session = %v, want exit code %d", err, exitNotFound)
+	}
+
+	tests := []struct {
+		kind string
+		want int
+	}{
+		{control.KindNotFound, 3},
+		{control.KindBusy, 4},
+		{control.KindStopped, 5},
+		{control.KindRequest, 6},
+		{control.KindTimeout, 124},
+		{control.KindCancelled, 130},
+		{control.KindOther, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			err := withExitCode(&control.Error{Kind: tt.kind, Message: "failed"})
+			if !errors.As(err, &exitErr) || exitErr.code != tt.want || err.Error() != "failed" {
+				t.Errorf("exit code for %s = %v, want %d", tt.kind, err, tt.want)
+			}
+		})
+	}
+	if withExitCode(nil) != nil {
+		t.Error("success got an exit code")
+	}
+}
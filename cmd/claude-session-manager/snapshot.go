@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+
+	"claude-session-manager/internal/control"
+	"claude-session-manager/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save and restore all sessions",
+}
+
+var snapshotSaveCmd = &cobra.Command{
+	Use:   "save <file>",
+	Short: "Save every session to a snapshot file",
+	Long: `Save writes the sessions of the ClaudePilot running on this machine to a
+snapshot file. When none is running it saves the archived sessions instead.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return withSessions(func(sessions control.Sessions) error {
+			snapshot, err := sessions.Snapshot()
+			if err != nil {
+				return err
+			}
+			if err := session.WriteSnapshot(args[0], snapshot); err != nil {
+				return err
+			}
+			fmt.Printf("Saved %d sessions to %s\n", len(snapshot.Sessions), args[0])
+			return nil
+		})
+	},
+}
+
+var snapshotLoadCmd = &cobra.Command{
+	Use:   "load <file>",
+	Short: "Start the TUI with the sessions from a snapshot file, all stopped",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snapshot, err := session.LoadSnapshot(args[0])
+		if err != nil {
+			return err
+		}
+		manager, err := newManager()
+		if err != nil {
+			return err
+		}
+		manager.Restore(snapshot)
+		return runTUI(manager)
+	},
+}
+
+func init() {
+	snapshotCmd.AddCommand(snapshotSaveCmd, snapshotLoadCmd)
+	rootCmd.AddCommand(snapshotCmd)
+}
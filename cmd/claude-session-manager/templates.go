@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"claude-session-manager/internal/config"
+	"claude-session-manager/internal/templates"
+	"github.com/spf13/cobra"
+)
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "List, show and render prompt templates",
+	Long: `Templates are text/template files ending in .tmpl in the templates directory
+of the config directory (~/.config/claudepilot/templates by default). Front
+matter between --- lines declares each variable the template takes, with an
+optional description and default.`,
+}
+
+var templatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the templates and what they are for",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		dir, err := templateDir()
+		if err != nil {
+			return err
+		}
+		list, err := templates.LoadDir(dir)
+		for _, t := range list {
+			names := make([]string, len(t.Variables))
+			for i, v := range t.Variables {
+				names[i] = v.Name
+			}
+			fmt.Printf("%s\t%s\t%s\n", t.Name, t.Description, strings.Join(names, ", "))
+		}
+		if err == nil && len(list) == 0 {
+			fmt.Fprintf(os.Stderr, "No templates in %s\n", dir)
+		}
+		return err
+	},
+}
+
+var templatesShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print a template's variables and body",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		t, err := findTemplate(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s (%s)\n", t.Name, t.Path)
+		if t.Description != "" {
+			fmt.Println(t.Description)
+		}
+		if len(t.Variables) > 0 {
+			fmt.Println("\nVariables:")
+		}
+		for _, v := range t.Variables {
+			line := "  " + v.Name
+			if v.Description != "" {
+				line += " - " + v.Description
+			}
+			if v.Required {
+				line += " (required)"
+			} else {
+				line += fmt.Sprintf(" (default %q)", v.Default)
+			}
+			fmt.Println(line)
+		}
+		fmt.Printf("\n%s", t.Body)
+		if !strings.HasSuffix(t.Body, "\n") {
+			fmt.Println()
+		}
+		return nil
+	},
+}
+
+var templatesRenderCmd = &cobra.Command{
+	Use:   "render <name> [variable=value]...",
+	Short: "Fill a template in and print the prompt",
+	Long: `Render prints the prompt a template produces. Values starting with @ are read
+from the named file, or from stdin for @-.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		t, err := findTemplate(args[0])
+		if err != nil {
+			return err
+		}
+		values := make(map[string]string)
+		for _, arg := range args[1:] {
+			name, value, ok := strings.Cut(arg, "=")
+			if !ok {
+				return fmt.Errorf("%q is not variable=value", arg)
+			}
+			if value, err = readValue(value); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			values[name] = value
+		}
+		prompt, err := t.Render(values)
+		if err != nil {
+			return err
+		}
+		fmt.Print(prompt)
+		if !strings.HasSuffix(prompt, "\n") {
+			fmt.Println()
+		}
+		return nil
+	},
+}
+
+func init() {
+	templatesCmd.AddCommand(templatesListCmd, templatesShowCmd, templatesRenderCmd)
+	rootCmd.AddCommand(templatesCmd)
+}
+
+// templateDir is the templates directory in the config directory.
+func templateDir() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "templates"), nil
+}
+
+func findTemplate(name string) (*templates.Template, error) {
+	dir, err := templateDir()
+	if err != nil {
+		return nil, err
+	}
+	return templates.Find(dir, name)
+}
+
+// readValue resolves @file and @- values.
+func readValue(value string) (string, error) {
+	path, ok := strings.CutPrefix(value, "@")
+	if !ok {
+		return value, nil
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	return strings.TrimSuffix(string(data), "\n"), err
+}
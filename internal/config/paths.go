@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const appName = "claudepilot"
+
+// DataDir is where ClaudePilot keeps session data: $CLAUDEPILOT_DATA_DIR if
+// set, otherwise $XDG_DATA_HOME/claudepilot or ~/.local/share/claudepilot.
+func DataDir() (string, error) {
+	if dir := os.Getenv("CLAUDEPILOT_DATA_DIR"); dir != "" {
+		return dir, nil
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, appName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", appName), nil
+}
+
+// DataPath joins elem onto the data directory and makes sure the parent
+// directory of the result exists.
+func DataPath(elem ...string) (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(append([]string{dir}, elem...)...)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ConfigDir is where ClaudePilot reads user configuration such as scripts:
+// $CLAUDEPILOT_CONFIG_DIR if set, otherwise $XDG_CONFIG_HOME/claudepilot or
+// ~/.config/claudepilot.
+func ConfigDir() (string, error) {
+	if dir := os.Getenv("CLAUDEPILOT_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, appName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", appName), nil
+}
@@ -0,0 +1,115 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+
+	"claude-session-manager/internal/session"
+)
+
+// Client sends commands to a running ClaudePilot.
+type Client struct {
+	path string
+}
+
+// Dial checks that a ClaudePilot is listening at path.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	conn.Close()
+	return &Client{path: path}, nil
+}
+
+// call sends req and returns the final response, passing streamed text to
+// onText. Cancelling ctx hangs up, which cancels the command.
+func (c *Client) call(ctx context.Context, req request, onText func(string)) (response, error) {
+	conn, err := net.Dial("unix", c.path)
+	if err != nil {
+		return response{}, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return response{}, err
+	}
+	decoder := json.NewDecoder(conn)
+	for {
+		var resp response
+		if err := decoder.Decode(&resp); err != nil {
+			if ctx.Err() != nil {
+				return response{}, ctx.Err()
+			}
+			return response{}, fmt.Errorf("lost the connection to ClaudePilot: %w", err)
+		}
+		if !resp.Done {
+			if onText != nil {
+				onText(resp.Text)
+			}
+			continue
+		}
+		if resp.Error != nil {
+			return resp, resp.Error
+		}
+		return resp, nil
+	}
+}
+
+func (c *Client) Create(opts CreateOptions) (Info, error) {
+	resp, err := c.call(context.Background(), request{Op: "create", Create: &opts}, nil)
+	if err != nil {
+		return Info{}, err
+	}
+	return *resp.Info, nil
+}
+
+func (c *Client) List() ([]Info, error) {
+	resp, err := c.call(context.Background(), request{Op: "list"}, nil)
+	return resp.Infos, err
+}
+
+func (c *Client) Status(ref string) (Info, error) {
+	resp, err := c.call(context.Background(), request{Op: "status", Ref: ref}, nil)
+	if err != nil {
+		return Info{}, err
+	}
+	return *resp.Info, nil
+}
+
+func (c *Client) Send(ctx context.Context, ref, prompt string, onText func(string)) (SendResult, error) {
+	resp, err := c.call(ctx, request{Op: "send", Ref: ref, Prompt: prompt}, onText)
+	if resp.Result == nil {
+		if err == nil {
+			err = errors.New("ClaudePilot sent no result")
+		}
+		return SendResult{}, err
+	}
+	return *resp.Result, err
+}
+
+func (c *Client) Kill(ref string) error {
+	_, err := c.call(context.Background(), request{Op: "kill", Ref: ref}, nil)
+	return err
+}
+
+func (c *Client) Logs(ref string, n int) ([]session.Entry, error) {
+	resp, err := c.call(context.Background(), request{Op: "logs", Ref: ref, N: n}, nil)
+	return resp.Entries, err
+}
+
+func (c *Client) Snapshot() (session.Snapshot, error) {
+	resp, err := c.call(context.Background(), request{Op: "snapshot"}, nil)
+	if err != nil {
+		return session.Snapshot{}, err
+	}
+	if resp.Snapshot == nil {
+		return session.Snapshot{}, errors.New("ClaudePilot sent no snapshot")
+	}
+	return *resp.Snapshot, nil
+}
@@ -0,0 +1,319 @@
+// Package control lets other processes drive the sessions of a running
+// ClaudePilot over a Unix socket, and runs the same commands against the
+// session archive when no ClaudePilot is running.
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"claude-session-manager/internal/session"
+	"claude-session-manager/internal/store"
+)
+
+// Sessions is what the session commands run against: a Client talking to a
+// running ClaudePilot, or a Local manager over the archive.
+type Sessions interface {
+	Create(opts CreateOptions) (Info, error)
+	List() ([]Info, error)
+	Status(ref string) (Info, error)
+	// Send sends prompt and waits for the reply, passing each piece of reply
+	// text to onText as it streams in.
+	Send(ctx context.Context, ref, prompt string, onText func(string)) (SendResult, error)
+	Kill(ref string) error
+	// Logs returns the last n transcript entries, or all of them for n < 1.
+	Logs(ref string, n int) ([]session.Entry, error)
+	// Snapshot captures every session that List would show.
+	Snapshot() (session.Snapshot, error)
+}
+
+// CreateOptions override the default config of a new session.
+type CreateOptions struct {
+	Name         string   `json:"name,omitempty"`
+	Alias        string   `json:"alias,omitempty"`
+	Model        string   `json:"model,omitempty"`
+	SystemPrompt string   `json:"system_prompt,omitempty"`
+	MaxTokens    int      `json:"max_tokens,omitempty"`
+	Temperature  *float64 `json:"temperature,omitempty"`
+}
+
+// Info describes a session. Open is set for sessions open in a running
+// ClaudePilot, as opposed to ones read from the archive.
+type Info struct {
+	ID         string        `json:"id"`
+	Name       string        `json:"name"`
+	Alias      string        `json:"alias,omitempty"`
+	Status     string        `json:"status"`
+	StopReason string        `json:"stop_reason,omitempty"`
+	Model      string        `json:"model"`
+	Messages   int           `json:"messages"`
+	Usage      session.Usage `json:"usage"`
+	Cost       float64       `json:"cost"`
+	LastError  string        `json:"last_error,omitempty"`
+	Open       bool          `json:"open"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// SendResult is a session after a send, with the reply it gave.
+type SendResult struct {
+	Session Info   `json:"session"`
+	Reply   string `json:"reply"`
+}
+
+func infoFromState(state session.SessionState, open bool) Info {
+	messages := 0
+	for _, entry := range state.Transcript {
+		if entry.Role == session.RoleUser || entry.Role == session.RoleAssistant {
+			messages++
+		}
+	}
+	return Info{
+		ID:         state.ID,
+		Name:       state.Name,
+		Alias:      state.Alias,
+		Status:     state.Status.String(),
+		StopReason: state.StopReason,
+		Model:      state.Config.Model,
+		Messages:   messages,
+		Usage:      state.Usage,
+		Cost:       state.Cost,
+		Open:       open,
+		CreatedAt:  state.CreatedAt,
+		UpdatedAt:  state.UpdatedAt,
+	}
+}
+
+// Kinds of error, kept when errors cross the socket so callers can tell
+// what went wrong.
+const (
+	KindNotFound  = "not_found"
+	KindBusy      = "busy"
+	KindStopped   = "stopped"
+	KindRequest   = "request"
+	KindTimeout   = "timeout"
+	KindCancelled = "cancelled"
+	KindOther     = "error"
+)
+
+// Error is an error reported by a running ClaudePilot.
+type Error struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Kind classifies err as one of the Kind constants.
+func Kind(err error) string {
+	var remote *Error
+	var sendErr *session.SendError
+	switch {
+	case errors.As(err, &remote):
+		return remote.Kind
+	case errors.Is(err, session.ErrSessionNotFound):
+		return KindNotFound
+	case errors.Is(err, session.ErrBusy):
+		return KindBusy
+	case errors.Is(err, session.ErrStopped), errors.Is(err, session.ErrBudgetExceeded),
+		errors.Is(err, session.ErrBackendClosed), errors.Is(err, session.ErrInvalidTransition):
+		return KindStopped
+	case errors.Is(err, context.DeadlineExceeded):
+		return KindTimeout
+	case errors.Is(err, context.Canceled):
+		return KindCancelled
+	case errors.As(err, &sendErr):
+		return KindRequest
+	}
+	return KindOther
+}
+
+// Local runs the commands against a manager in this process. Sessions that
+// are not open are looked up in the archive; sending to or killing one
+// reopens it, and closing the manager archives it again.
+type Local struct {
+	Manager *session.Manager
+	Store   *store.Store
+
+	// open is set when Manager belongs to a running ClaudePilot, whose own
+	// sessions are the ones to list.
+	open bool
+}
+
+func (l *Local) Create(opts CreateOptions) (Info, error) {
+	config := l.Manager.DefaultConfig()
+	if opts.Model != "" {
+		config.Model = opts.Model
+	}
+	if opts.SystemPrompt != "" {
+		config.SystemPrompt = opts.SystemPrompt
+	}
+	if opts.MaxTokens > 0 {
+		config.MaxTokens = opts.MaxTokens
+	}
+	if opts.Temperature != nil {
+		config.Temperature = opts.Temperature
+	}
+	name := opts.Name
+	if name == "" {
+		name = "CLI session"
+	}
+	// Without a running ClaudePilot the archived sessions are the ones in
+	// use, so their aliases are taken.
+	if opts.Alias != "" && !l.open {
+		if state, err := l.archived("@" + opts.Alias); err == nil && state.Alias == opts.Alias {
+			return Info{}, fmt.Errorf("alias %q is already used by session %s", opts.Alias, state.ID)
+		}
+	}
+
+	sess := l.Manager.CreateSession(name, config)
+	if opts.Alias != "" {
+		if err := l.Manager.SetAlias(sess.ID, opts.Alias); err != nil {
+			l.Manager.RemoveSession(sess.ID)
+			return Info{}, err
+		}
+	}
+	return l.info(sess), nil
+}
+
+// List returns the open sessions or, without a running ClaudePilot, the
+// archived ones that were not killed, oldest first.
+func (l *Local) List() ([]Info, error) {
+	var infos []Info
+	seen := make(map[string]bool)
+	for _, sess := range l.Manager.GetSessions() {
+		infos = append(infos, l.info(sess))
+		seen[sess.ID] = true
+	}
+	if l.open || l.Store == nil {
+		return infos, nil
+	}
+	for _, state := range l.Store.States() {
+		if !seen[state.ID] && state.Status != session.StatusKilled {
+			infos = append(infos, infoFromState(state, false))
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].CreatedAt.Before(infos[j].CreatedAt) })
+	return infos, nil
+}
+
+func (l *Local) Status(ref string) (Info, error) {
+	if sess, err := l.Manager.Resolve(ref); err == nil {
+		return l.info(sess), nil
+	}
+	state, err := l.archived(ref)
+	if err != nil {
+		return Info{}, err
+	}
+	return infoFromState(state, false), nil
+}
+
+func (l *Local) Send(ctx context.Context, ref, prompt string, onText func(string)) (SendResult, error) {
+	sess, err := l.reopen(ref)
+	if err != nil {
+		return SendResult{}, err
+	}
+	err = sess.Send(ctx, prompt, func(chunk session.Chunk) {
+		if chunk.Type == session.ChunkText && onText != nil {
+			onText(chunk.Text)
+		}
+	})
+	result := SendResult{Session: l.info(sess)}
+	if err != nil {
+		return result, err
+	}
+	result.Reply, _ = sess.Select(session.Selection{Kind: session.SelectLastReply})
+	return result, nil
+}
+
+func (l *Local) Kill(ref string) error {
+	sess, err := l.reopen(ref)
+	if err != nil {
+		return err
+	}
+	return l.Manager.KillSession(sess.ID, "")
+}
+
+func (l *Local) Logs(ref string, n int) ([]session.Entry, error) {
+	var transcript []session.Entry
+	if sess, err := l.Manager.Resolve(ref); err == nil {
+		transcript = sess.GetTranscript()
+	} else {
+		state, err := l.archived(ref)
+		if err != nil {
+			return nil, err
+		}
+		transcript = state.Transcript
+	}
+	if n > 0 && len(transcript) > n {
+		transcript = transcript[len(transcript)-n:]
+	}
+	return transcript, nil
+}
+
+// Snapshot captures the open sessions or, without a running ClaudePilot, the
+// archived ones that were not killed.
+func (l *Local) Snapshot() (session.Snapshot, error) {
+	snapshot := l.Manager.Snapshot()
+	if l.open || l.Store == nil {
+		return snapshot, nil
+	}
+	seen := make(map[string]bool)
+	for _, state := range snapshot.Sessions {
+		seen[state.ID] = true
+	}
+	for _, state := range l.Store.States() {
+		if !seen[state.ID] && state.Status != session.StatusKilled {
+			snapshot.Sessions = append(snapshot.Sessions, state)
+		}
+	}
+	sort.SliceStable(snapshot.Sessions, func(i, j int) bool {
+		return snapshot.Sessions[i].CreatedAt.Before(snapshot.Sessions[j].CreatedAt)
+	})
+	return snapshot, nil
+}
+
+// reopen returns the open session ref names, reopening and starting it if
+// it is only in the archive.
+func (l *Local) reopen(ref string) (*session.Session, error) {
+	if sess, err := l.Manager.Resolve(ref); err == nil {
+		return sess, nil
+	}
+	state, err := l.archived(ref)
+	if err != nil {
+		return nil, err
+	}
+	sess := l.Manager.Reopen(state)
+	if err := sess.Start(context.Background()); err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
+
+// archived finds a session in the archive. Killed sessions are gone.
+func (l *Local) archived(ref string) (session.SessionState, error) {
+	if l.Store == nil {
+		return session.SessionState{}, fmt.Errorf("%w: %s", session.ErrSessionNotFound, ref)
+	}
+	state, err := l.Store.Resolve(ref)
+	if err != nil {
+		return session.SessionState{}, err
+	}
+	if state.Status == session.StatusKilled {
+		return session.SessionState{}, fmt.Errorf("%w: %s was killed", session.ErrSessionNotFound, state.Name)
+	}
+	return state, nil
+}
+
+func (l *Local) info(sess *session.Session) Info {
+	info := infoFromState(sess.State(), l.open)
+	if sendErr := sess.GetLastError(); sendErr != nil && sess.GetStatus() == session.StatusError {
+		info.LastError = sendErr.Error()
+	}
+	return info
+}
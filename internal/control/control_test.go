@@ -0,0 +1,199 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"claude-session-manager/internal/session"
+	"claude-session-manager/internal/store"
+)
+
+// serve starts a server for m on a socket in a temporary directory and
+// returns a client for it.
+func serve(t *testing.T, m *session.Manager, st *store.Store) *Client {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "control.sock")
+	server, err := Serve(path, m, st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+	client, err := Dial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestSnapshot(t *testing.T) {
+	m := session.NewManager()
+	open := m.CreateSession("open", m.DefaultConfig())
+	if err := open.SendInput("hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	archived := session.NewManager().CreateSession("archived", m.DefaultConfig()).State()
+	killed := session.NewManager().CreateSession("killed", m.DefaultConfig()).State()
+	killed.Status = session.StatusKilled
+	for _, state := range []session.SessionState{archived, killed} {
+		if err := st.Archive(state); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A running ClaudePilot snapshots its own sessions.
+	snapshot, err := serve(t, m, st).Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Sessions) != 1 || snapshot.Sessions[0].ID != open.ID || len(snapshot.Sessions[0].Transcript) != 2 {
+		t.Errorf("served snapshot = %+v, want the open session", snapshot.Sessions)
+	}
+
+	// Without one, the archived sessions that were not killed are saved.
+	local := &Local{Manager: session.NewManager(), Store: st}
+	snapshot, err = local.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Sessions) != 1 || snapshot.Sessions[0].ID != archived.ID {
+		t.Errorf("local snapshot = %+v, want the archived session", snapshot.Sessions)
+	}
+	if snapshot.Version != session.SnapshotVersion {
+		t.Errorf("snapshot version = %d", snapshot.Version)
+	}
+}
+
+// failingBackend fails every request without a retry.
+type failingBackend struct{}
+
+func (failingBackend) Send(ctx context.Context, req session.Request, emit func(session.Chunk)) error {
+	return errors.New("invalid request")
+}
+func (failingBackend) Cancel()                            {}
+func (failingBackend) Close() error                       { return nil }
+func (failingBackend) Capabilities() session.Capabilities { return session.Capabilities{} }
+
+func TestErrorKinds(t *testing.T) {
+	m := session.NewManager()
+	m.SetBackendFactory(func(s *session.Session) session.Backend {
+		if s.Name == "broken" {
+			return failingBackend{}
+		}
+		backend := session.NewFakeBackend()
+		backend.Delay = 100 * time.Millisecond
+		return backend
+	})
+	client := serve(t, m, nil)
+	create := func(name string) string {
+		info, err := client.Create(CreateOptions{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.ID
+	}
+	send := func(ctx context.Context, id string) error {
+		_, err := client.Send(ctx, id, "one two three four five", nil)
+		return err
+	}
+
+	tests := []struct {
+		name string
+		run  func() error
+		want string
+	}{
+		{
+			name: "unknown session",
+			run:  func() error { _, err := client.Status("nope"); return err },
+			want: KindNotFound,
+		},
+		{
+			name: "busy",
+			run: func() error {
+				id := create("busy")
+				go send(context.Background(), id)
+				sess, _ := m.Resolve(id)
+				for !sess.Busy() {
+					time.Sleep(10 * time.Millisecond)
+				}
+				return send(context.Background(), id)
+			},
+			want: KindBusy,
+		},
+		{
+			name: "stopped",
+			run: func() error {
+				id := create("stopped")
+				sess, _ := m.Resolve(id)
+				if err := sess.Stop("paused"); err != nil {
+					t.Fatal(err)
+				}
+				return send(context.Background(), id)
+			},
+			want: KindStopped,
+		},
+		{
+			name: "request failed",
+			run:  func() error { return send(context.Background(), create("broken")) },
+			want: KindRequest,
+		},
+		{
+			name: "timeout",
+			run: func() error {
+				ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+				defer cancel()
+				return send(ctx, create("slow"))
+			},
+			want: KindTimeout,
+		},
+		{
+			name: "interrupted",
+			run: func() error {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(50*time.Millisecond, cancel)
+				return send(ctx, create("interrupted"))
+			},
+			want: KindCancelled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if got := Kind(err); got != tt.want {
+				t.Errorf("Kind(%v) = %s, want %s", err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendStreams(t *testing.T) {
+	m := session.NewManager()
+	client := serve(t, m, nil)
+	info, err := client.Create(CreateOptions{Name: "streamer", Alias: "s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var streamed strings.Builder
+	result, err := client.Send(context.Background(), "@s", "hello", func(text string) { streamed.WriteString(text) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Reply != "Claude response to: hello" || streamed.String() != result.Reply {
+		t.Errorf("reply %q, streamed %q", result.Reply, streamed.String())
+	}
+	if result.Session.ID != info.ID || result.Session.Messages != 2 {
+		t.Errorf("result session = %+v", result.Session)
+	}
+	entries, err := client.Logs(info.ID, 1)
+	if err != nil || len(entries) != 1 || entries[0].Text() != result.Reply {
+		t.Errorf("logs = %+v, %v", entries, err)
+	}
+}
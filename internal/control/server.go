@@ -0,0 +1,156 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	"claude-session-manager/internal/session"
+	"claude-session-manager/internal/store"
+)
+
+// request is one command sent over the socket. Each connection carries a
+// single request, answered by zero or more text responses while a reply
+// streams and then one final response.
+type request struct {
+	Op     string         `json:"op"`
+	Ref    string         `json:"ref,omitempty"`
+	Prompt string         `json:"prompt,omitempty"`
+	N      int            `json:"n,omitempty"`
+	Create *CreateOptions `json:"create,omitempty"`
+}
+
+type response struct {
+	Text     string            `json:"text,omitempty"`
+	Done     bool              `json:"done,omitempty"`
+	Error    *Error            `json:"error,omitempty"`
+	Info     *Info             `json:"info,omitempty"`
+	Infos    []Info            `json:"infos,omitempty"`
+	Result   *SendResult       `json:"result,omitempty"`
+	Entries  []session.Entry   `json:"entries,omitempty"`
+	Snapshot *session.Snapshot `json:"snapshot,omitempty"`
+}
+
+// Server answers commands from other processes with a manager's sessions.
+type Server struct {
+	local    *Local
+	listener net.Listener
+	wg       sync.WaitGroup
+}
+
+// Serve listens on the socket at path. It fails if another process is
+// already serving there; a socket left behind by one that died is replaced.
+func Serve(path string, m *session.Manager, st *store.Store) (*Server, error) {
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("another ClaudePilot is already listening on %s", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{local: &Local{Manager: m, Store: st, open: true}, listener: listener}
+	s.wg.Add(1)
+	go s.accept()
+	return s, nil
+}
+
+// Close stops accepting commands and removes the socket. Commands already
+// running are left to finish.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	decoder := json.NewDecoder(conn)
+	var req request
+	if err := decoder.Decode(&req); err != nil {
+		return
+	}
+	// The client hanging up cancels whatever it asked for.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		var discard json.RawMessage
+		decoder.Decode(&discard)
+		cancel()
+	}()
+
+	var (
+		mu      sync.Mutex
+		encoder = json.NewEncoder(conn)
+	)
+	write := func(resp response) {
+		mu.Lock()
+		defer mu.Unlock()
+		encoder.Encode(resp)
+	}
+
+	resp, err := s.run(ctx, req, func(text string) {
+		write(response{Text: text})
+	})
+	if err != nil {
+		resp.Error = &Error{Kind: Kind(err), Message: err.Error()}
+	}
+	resp.Done = true
+	write(resp)
+}
+
+func (s *Server) run(ctx context.Context, req request, onText func(string)) (response, error) {
+	var resp response
+	switch req.Op {
+	case "create":
+		if req.Create == nil {
+			req.Create = &CreateOptions{}
+		}
+		info, err := s.local.Create(*req.Create)
+		resp.Info = &info
+		return resp, err
+	case "list":
+		infos, err := s.local.List()
+		resp.Infos = infos
+		return resp, err
+	case "status":
+		info, err := s.local.Status(req.Ref)
+		resp.Info = &info
+		return resp, err
+	case "send":
+		result, err := s.local.Send(ctx, req.Ref, req.Prompt, onText)
+		resp.Result = &result
+		return resp, err
+	case "kill":
+		return resp, s.local.Kill(req.Ref)
+	case "logs":
+		entries, err := s.local.Logs(req.Ref, req.N)
+		resp.Entries = entries
+		return resp, err
+	case "snapshot":
+		snapshot, err := s.local.Snapshot()
+		resp.Snapshot = &snapshot
+		return resp, err
+	}
+	return resp, fmt.Errorf("unknown command %q", req.Op)
+}
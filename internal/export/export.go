@@ -0,0 +1,131 @@
+// Package export renders session transcripts as Markdown, standalone HTML or
+// JSON for use outside the TUI.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"claude-session-manager/internal/session"
+)
+
+type Format string
+
+const (
+	Markdown Format = "md"
+	HTML     Format = "html"
+	JSON     Format = "json"
+)
+
+// Formats lists the supported formats.
+var Formats = []Format{Markdown, HTML, JSON}
+
+// ParseFormat accepts a format name or file extension, such as "markdown",
+// "md" or ".html".
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "md", "markdown":
+		return Markdown, nil
+	case "html", "htm":
+		return HTML, nil
+	case "json":
+		return JSON, nil
+	}
+	return "", fmt.Errorf("unknown export format %q (want md, html or json)", name)
+}
+
+// FormatForPath picks the format from path's extension.
+func FormatForPath(path string) (Format, error) {
+	return ParseFormat(filepath.Ext(path))
+}
+
+// Extension is the file extension for f, including the dot.
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// Write renders the session to w in format f.
+func Write(w io.Writer, state session.SessionState, f Format) error {
+	switch f {
+	case Markdown:
+		return writeMarkdown(w, state)
+	case HTML:
+		return writeHTML(w, state)
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(state)
+	}
+	return fmt.Errorf("unknown export format %q", f)
+}
+
+const timeLayout = "2006-01-02 15:04:05"
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(timeLayout)
+}
+
+func roleTitle(entry session.Entry) string {
+	switch entry.Role {
+	case session.RoleUser:
+		if entry.Source != "" {
+			return "User · piped from " + entry.Source
+		}
+		return "User"
+	case session.RoleAssistant:
+		return "Assistant"
+	case session.RoleTool:
+		for _, block := range entry.Blocks {
+			if block.IsError {
+				return "Tool result (error)"
+			}
+		}
+		return "Tool result"
+	default:
+		return "System"
+	}
+}
+
+func formatUsage(usage session.Usage) string {
+	text := fmt.Sprintf("%d in / %d out tokens", usage.InputTokens, usage.OutputTokens)
+	if cached := usage.CacheReadTokens + usage.CacheWriteTokens; cached > 0 {
+		text += fmt.Sprintf(", %d cached", cached)
+	}
+	return text
+}
+
+// toolInput pretty-prints a tool call's JSON input, falling back to the raw
+// text when it is not JSON.
+func toolInput(text string) (string, string) {
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return text, ""
+	}
+	pretty, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return text, ""
+	}
+	return string(pretty), "json"
+}
+
+// summaryLines describes the session as label/value pairs.
+func summaryLines(state session.SessionState) [][2]string {
+	lines := [][2]string{{"Session", state.ID}}
+	if state.Alias != "" {
+		lines = append(lines, [2]string{"Alias", "@" + state.Alias})
+	}
+	lines = append(lines,
+		[2]string{"Settings", state.Config.Summary()},
+		[2]string{"Created", formatTime(state.CreatedAt)},
+		[2]string{"Updated", formatTime(state.UpdatedAt)},
+		[2]string{"Usage", fmt.Sprintf("%s · $%.4f", formatUsage(state.Usage), state.Cost)},
+	)
+	return lines
+}
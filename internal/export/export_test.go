@@ -0,0 +1,153 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"claude-session-manager/internal/session"
+)
+
+func TestSplitProse(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []htmlPart
+	}{
+		{
+			name: "paragraphs",
+			text: "One\ntwo\n\nThree",
+			want: []htmlPart{{Prose: "<p>One<br>\ntwo</p>\n<p>Three</p>"}},
+		},
+		{
+			name: "fenced code",
+			text: "Run this:\n```go\nfmt.Println(\"hi\")\n```\nDone.",
+			want: []htmlPart{
+				{Prose: "<p>Run this:</p>"},
+				{Code: `fmt.Println("hi")`, Lang: "go"},
+				{Prose: "<p>Done.</p>"},
+			},
+		},
+		{
+			name: "longer fence",
+			text: "````md\n```\nnested\n```\n````",
+			want: []htmlPart{{Code: "```\nnested\n```", Lang: "md"}},
+		},
+		{
+			name: "unclosed fence",
+			text: "Start:\n```sh\nls\n\nrm -rf build",
+			want: []htmlPart{
+				{Prose: "<p>Start:</p>"},
+				{Code: "ls\n\nrm -rf build", Lang: "sh"},
+			},
+		},
+		{
+			name: "markup in prose",
+			text: `<script>alert("x")</script> & <b>bold</b>`,
+			want: []htmlPart{{Prose: "<p>&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; &amp; &lt;b&gt;bold&lt;/b&gt;</p>"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitProse(tt.text)
+			if len(got) != len(tt.want) {
+				t.Fatalf("splitProse() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("part %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func render(t *testing.T, f Format, name string, entries ...session.Entry) string {
+	t.Helper()
+	var out strings.Builder
+	if err := Write(&out, session.SessionState{ID: "s1", Name: name, Transcript: entries}, f); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestWriteHTMLEscapes(t *testing.T) {
+	injection := `</code></pre><script>alert(document.cookie)</script>`
+	tests := []struct {
+		name  string
+		title string
+		entry session.Entry
+		want  string
+	}{
+		{
+			name:  "prose",
+			entry: session.TextEntry(session.RoleUser, "Look: "+injection),
+			want:  "<p>Look: &lt;/code&gt;&lt;/pre&gt;&lt;script&gt;alert(document.cookie)&lt;/script&gt;</p>",
+		},
+		{
+			name:  "fenced code",
+			entry: session.TextEntry(session.RoleAssistant, "```html\n"+injection+"\n```"),
+			want:  `<pre><code class="language-html">&lt;/code&gt;&lt;/pre&gt;&lt;script&gt;alert(document.cookie)&lt;/script&gt;</code></pre>`,
+		},
+		{
+			name: "tool result",
+			entry: session.Entry{Role: session.RoleTool, Blocks: []session.ContentBlock{
+				{Type: session.BlockToolResult, Text: injection + "\n", IsError: true},
+			}},
+			want: "<pre><code>&lt;/code&gt;&lt;/pre&gt;&lt;script&gt;alert(document.cookie)&lt;/script&gt;</code></pre>",
+		},
+		{
+			name:  "session name",
+			title: "<script>alert(1)</script>",
+			entry: session.TextEntry(session.RoleUser, "hi"),
+			want:  "<h1>&lt;script&gt;alert(1)&lt;/script&gt;</h1>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := render(t, HTML, tt.title, tt.entry)
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output lacks %s:\n%s", tt.want, out)
+			}
+			if strings.Contains(out, "<script>") {
+				t.Errorf("output has an unescaped script tag:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestWriteMarkdownFences(t *testing.T) {
+	tests := []struct {
+		name  string
+		block session.ContentBlock
+		want  string
+	}{
+		{
+			name:  "tool call",
+			block: session.ContentBlock{Type: session.BlockToolUse, ToolName: "Read", Text: `{"file_path":"a.go"}`},
+			want:  "**Tool call: Read**\n\n```json\n{\n  \"file_path\": \"a.go\"\n}\n```\n\n",
+		},
+		{
+			name:  "tool call that is not JSON",
+			block: session.ContentBlock{Type: session.BlockToolUse, ToolName: "Bash", Text: "ls"},
+			want:  "**Tool call: Bash**\n\n```\nls\n```\n\n",
+		},
+		{
+			name:  "result with a fence inside",
+			block: session.ContentBlock{Type: session.BlockToolResult, Text: "```go\nx := 1\n```\n"},
+			want:  "````\n```go\nx := 1\n```\n````\n\n",
+		},
+		{
+			name:  "prose is left alone",
+			block: session.ContentBlock{Type: session.BlockText, Text: "```go\nx := 1\n```\n"},
+			want:  "## System\n\n```go\nx := 1\n```\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := render(t, Markdown, "fences", session.Entry{Role: session.RoleSystem, Blocks: []session.ContentBlock{tt.block}})
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output lacks %q:\n%s", tt.want, out)
+			}
+		})
+	}
+}
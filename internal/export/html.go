@@ -0,0 +1,177 @@
+package export
+
+import (
+	"html"
+	"html/template"
+	"io"
+	"strings"
+
+	"claude-session-manager/internal/session"
+)
+
+type htmlEntry struct {
+	Class string
+	Title string
+	Time  string
+	Parts []htmlPart
+	Usage string
+}
+
+// htmlPart is a run of prose or a code block.
+type htmlPart struct {
+	Prose    template.HTML
+	Code     string
+	Lang     string
+	ToolName string
+}
+
+var htmlTemplate = template.Must(template.New("transcript").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 52rem; margin: 2rem auto; padding: 0 1rem; color: #1f2937; line-height: 1.5; }
+h1 { margin-bottom: .5rem; }
+dl.summary { display: grid; grid-template-columns: max-content 1fr; gap: .2rem 1rem; color: #4b5563; font-size: .9rem; }
+dl.summary dt { font-weight: 600; }
+dl.summary dd { margin: 0; }
+section.entry { border-left: 4px solid #d1d5db; margin: 1.5rem 0; padding: .25rem 1rem; }
+section.user { border-color: #7c3aed; }
+section.assistant { border-color: #06b6d4; }
+section.tool { border-color: #9ca3af; }
+section.tool-error { border-color: #ef4444; }
+section.system { border-color: #f59e0b; }
+header { font-weight: 600; }
+header time { font-weight: normal; color: #6b7280; font-size: .85rem; margin-left: .5rem; }
+pre { background: #f3f4f6; padding: .75rem; overflow-x: auto; border-radius: 4px; font-size: .85rem; }
+.tool-name { font-size: .85rem; color: #4b5563; margin-bottom: -.5rem; }
+.usage { color: #6b7280; font-size: .8rem; font-style: italic; }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+<dl class="summary">
+{{- range .Summary}}
+<dt>{{index . 0}}</dt><dd>{{index . 1}}</dd>
+{{- end}}
+</dl>
+{{range .Entries}}
+<section class="entry {{.Class}}">
+<header>{{.Title}}{{if .Time}}<time>{{.Time}}</time>{{end}}</header>
+{{- range .Parts}}
+{{- if .ToolName}}
+<p class="tool-name">Tool call: <code>{{.ToolName}}</code></p>
+{{- end}}
+{{- if .Prose}}
+{{.Prose}}
+{{- else}}
+<pre><code{{if .Lang}} class="language-{{.Lang}}"{{end}}>{{.Code}}</code></pre>
+{{- end}}
+{{- end}}
+{{- if .Usage}}
+<p class="usage">{{.Usage}}</p>
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+`))
+
+func writeHTML(w io.Writer, state session.SessionState) error {
+	data := struct {
+		Name    string
+		Summary [][2]string
+		Entries []htmlEntry
+	}{Name: state.Name, Summary: summaryLines(state)}
+
+	for _, entry := range state.Transcript {
+		item := htmlEntry{
+			Class: string(entry.Role),
+			Title: roleTitle(entry),
+			Time:  formatTime(entry.Timestamp),
+		}
+		for _, block := range entry.Blocks {
+			switch block.Type {
+			case session.BlockText:
+				item.Parts = append(item.Parts, splitProse(block.Text)...)
+			case session.BlockToolUse:
+				input, lang := toolInput(block.Text)
+				item.Parts = append(item.Parts, htmlPart{Code: input, Lang: lang, ToolName: block.ToolName})
+			case session.BlockToolResult:
+				if block.IsError {
+					item.Class = "tool-error"
+				}
+				item.Parts = append(item.Parts, htmlPart{Code: strings.TrimRight(block.Text, "\n")})
+			}
+		}
+		if entry.Usage != nil {
+			item.Usage = formatUsage(*entry.Usage)
+		}
+		data.Entries = append(data.Entries, item)
+	}
+	return htmlTemplate.Execute(w, data)
+}
+
+// splitProse separates Markdown-style fenced code blocks from the prose
+// around them. Prose becomes paragraphs at blank lines.
+func splitProse(text string) []htmlPart {
+	var (
+		parts []htmlPart
+		prose []string
+		code  []string
+		fence string
+		lang  string
+	)
+	flushProse := func() {
+		if paragraphs := renderParagraphs(prose); paragraphs != "" {
+			parts = append(parts, htmlPart{Prose: template.HTML(paragraphs)})
+		}
+		prose = nil
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence == "" && strings.HasPrefix(trimmed, "```"):
+			flushProse()
+			fence = trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, "`"))]
+			lang = strings.TrimSpace(strings.TrimLeft(trimmed, "`"))
+		case fence != "" && trimmed == fence:
+			parts = append(parts, htmlPart{Code: strings.Join(code, "\n"), Lang: lang})
+			code, fence, lang = nil, "", ""
+		case fence != "":
+			code = append(code, line)
+		default:
+			prose = append(prose, line)
+		}
+	}
+	if fence != "" {
+		// An unterminated fence still reads best as code.
+		parts = append(parts, htmlPart{Code: strings.Join(code, "\n"), Lang: lang})
+	}
+	flushProse()
+	return parts
+}
+
+func renderParagraphs(lines []string) string {
+	var (
+		out       strings.Builder
+		paragraph []string
+	)
+	flush := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + strings.Join(paragraph, "<br>\n") + "</p>\n")
+			paragraph = nil
+		}
+	}
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		paragraph = append(paragraph, html.EscapeString(line))
+	}
+	flush()
+	return strings.TrimSuffix(out.String(), "\n")
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"claude-session-manager/internal/session"
+)
+
+func writeMarkdown(w io.Writer, state session.SessionState) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "# %s\n\n", state.Name)
+	for _, line := range summaryLines(state) {
+		fmt.Fprintf(out, "- **%s:** %s\n", line[0], line[1])
+	}
+	fmt.Fprintln(out)
+
+	for _, entry := range state.Transcript {
+		fmt.Fprintf(out, "## %s", roleTitle(entry))
+		if ts := formatTime(entry.Timestamp); ts != "" {
+			fmt.Fprintf(out, " · %s", ts)
+		}
+		fmt.Fprint(out, "\n\n")
+
+		for _, block := range entry.Blocks {
+			switch block.Type {
+			case session.BlockText:
+				// Prose, including any code blocks in it, is already Markdown.
+				fmt.Fprintf(out, "%s\n\n", strings.TrimRight(block.Text, "\n"))
+			case session.BlockToolUse:
+				input, lang := toolInput(block.Text)
+				fmt.Fprintf(out, "**Tool call: %s**\n\n", block.ToolName)
+				writeFence(out, input, lang)
+			case session.BlockToolResult:
+				writeFence(out, block.Text, "")
+			}
+		}
+		if entry.Usage != nil {
+			fmt.Fprintf(out, "_%s_\n\n", formatUsage(*entry.Usage))
+		}
+	}
+	return out.Flush()
+}
+
+// writeFence writes text as a fenced code block whose fence is longer than
+// any run of backticks inside it.
+func writeFence(w io.Writer, text, lang string) {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(w, "%s%s\n%s\n%s\n\n", fence, lang, strings.TrimRight(text, "\n"), fence)
+}
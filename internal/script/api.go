@@ -0,0 +1,439 @@
+package script
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"claude-session-manager/internal/session"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const sessionType = "session"
+
+// waitPollInterval is how often wait checks a session that is busy with a
+// prompt the script did not send.
+const waitPollInterval = 100 * time.Millisecond
+
+// runtime is the state one run of a script shares between its functions.
+// Every session has a single handle, so the same session compares equal
+// however the script got hold of it.
+type runtime struct {
+	env     Env
+	handles map[string]*handle
+}
+
+// handle is a session as a script sees it. pending holds the outcome of a
+// prompt passed to submit until wait collects it.
+type handle struct {
+	sess    *session.Session
+	value   *lua.LUserData
+	pending chan error
+}
+
+func (r *runtime) install(L *lua.LState) {
+	mt := L.NewTypeMetatable(sessionType)
+	L.SetField(mt, "__index", L.NewFunction(r.index))
+	L.SetField(mt, "__tostring", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LString(r.check(L, 1).sess.DisplayName()))
+		return 1
+	}))
+
+	for name, fn := range map[string]lua.LGFunction{
+		"session":  r.newSession,
+		"get":      r.get,
+		"sessions": r.sessions,
+		"wait":     r.waitAll,
+		"sleep":    r.sleep,
+		"print":    r.print,
+	} {
+		L.SetGlobal(name, L.NewFunction(fn))
+	}
+	L.SetGlobal("scratchpad", L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"read":  r.readScratchpad,
+		"write": r.writeScratchpad,
+	}))
+
+	args := L.NewTable()
+	for _, arg := range r.env.Args {
+		args.Append(lua.LString(arg))
+	}
+	L.SetGlobal("args", args)
+	if r.env.Selected != nil {
+		L.SetGlobal("selected", r.push(L, r.env.Selected))
+	}
+}
+
+// methods are looked up by index when a field is not one of a session's
+// plain values.
+var methods = map[string]func(r *runtime, L *lua.LState) int{
+	"status":     (*runtime).status,
+	"busy":       (*runtime).busy,
+	"send":       (*runtime).send,
+	"submit":     (*runtime).submit,
+	"wait":       (*runtime).wait,
+	"reply":      (*runtime).reply,
+	"last":       (*runtime).last,
+	"transcript": (*runtime).transcript,
+	"pipe":       (*runtime).pipe,
+	"note":       (*runtime).note,
+	"usage":      (*runtime).usage,
+	"start":      (*runtime).start,
+	"stop":       (*runtime).stop,
+	"kill":       (*runtime).kill,
+}
+
+func (r *runtime) index(L *lua.LState) int {
+	h := r.check(L, 1)
+	key := L.CheckString(2)
+	switch key {
+	case "id":
+		L.Push(lua.LString(h.sess.ID))
+	case "name":
+		L.Push(lua.LString(h.sess.Name))
+	case "alias":
+		L.Push(lua.LString(h.sess.GetAlias()))
+	default:
+		method, ok := methods[key]
+		if !ok {
+			L.Push(lua.LNil)
+			return 1
+		}
+		L.Push(L.NewFunction(func(L *lua.LState) int { return method(r, L) }))
+	}
+	return 1
+}
+
+func (r *runtime) push(L *lua.LState, sess *session.Session) lua.LValue {
+	if h, ok := r.handles[sess.ID]; ok {
+		return h.value
+	}
+	h := &handle{sess: sess, value: L.NewUserData()}
+	h.value.Value = h
+	L.SetMetatable(h.value, L.GetTypeMetatable(sessionType))
+	r.handles[sess.ID] = h
+	return h.value
+}
+
+func (r *runtime) check(L *lua.LState, n int) *handle {
+	ud := L.CheckUserData(n)
+	h, ok := ud.Value.(*handle)
+	if !ok {
+		L.ArgError(n, "session expected")
+	}
+	return h
+}
+
+func (r *runtime) fail(L *lua.LState, err error) {
+	L.RaiseError("%s", err.Error())
+}
+
+// session(name) or session{name=, alias=, model=, system=, max_tokens=,
+// temperature=, scratchpads={...}} creates a session over the manager's
+// default config.
+func (r *runtime) newSession(L *lua.LState) int {
+	m := r.env.Manager
+	config := m.DefaultConfig()
+	var name, alias string
+
+	switch arg := L.CheckAny(1).(type) {
+	case lua.LString:
+		name = string(arg)
+	case *lua.LTable:
+		name = stringField(L, arg, "name")
+		alias = stringField(L, arg, "alias")
+		if v := stringField(L, arg, "model"); v != "" {
+			config.Model = v
+		}
+		if v := stringField(L, arg, "system"); v != "" {
+			config.SystemPrompt = v
+		}
+		if v, ok := arg.RawGetString("max_tokens").(lua.LNumber); ok {
+			config.MaxTokens = int(v)
+		}
+		if v, ok := arg.RawGetString("temperature").(lua.LNumber); ok {
+			temperature := float64(v)
+			config.Temperature = &temperature
+		}
+		if pads, ok := arg.RawGetString("scratchpads").(*lua.LTable); ok {
+			config.Scratchpads = nil
+			pads.ForEach(func(_, v lua.LValue) {
+				config.Scratchpads = append(config.Scratchpads, v.String())
+			})
+		}
+	default:
+		L.ArgError(1, "name or table expected")
+	}
+	if name == "" {
+		name = "script"
+	}
+
+	for _, pad := range config.Scratchpads {
+		if err := m.CreateScratchpad(pad); err != nil {
+			r.fail(L, err)
+		}
+	}
+	sess := m.CreateSession(name, config)
+	if alias != "" {
+		if err := m.SetAlias(sess.ID, alias); err != nil {
+			m.RemoveSession(sess.ID)
+			r.fail(L, err)
+		}
+	}
+	L.Push(r.push(L, sess))
+	return 1
+}
+
+func stringField(L *lua.LState, t *lua.LTable, key string) string {
+	switch v := t.RawGetString(key).(type) {
+	case *lua.LNilType:
+		return ""
+	case lua.LString:
+		return string(v)
+	default:
+		L.RaiseError("%s: string expected, got %s", key, v.Type())
+		return ""
+	}
+}
+
+// get(ref) finds a session by anything Resolve accepts, or returns nil and
+// the reason.
+func (r *runtime) get(L *lua.LState) int {
+	sess, err := r.env.Manager.Resolve(L.CheckString(1))
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	L.Push(r.push(L, sess))
+	return 1
+}
+
+func (r *runtime) sessions(L *lua.LState) int {
+	list := L.NewTable()
+	for _, sess := range r.env.Manager.GetSessions() {
+		list.Append(r.push(L, sess))
+	}
+	L.Push(list)
+	return 1
+}
+
+func (r *runtime) status(L *lua.LState) int {
+	L.Push(lua.LString(r.check(L, 1).sess.GetStatus().String()))
+	return 1
+}
+
+func (r *runtime) busy(L *lua.LState) int {
+	L.Push(lua.LBool(r.check(L, 1).sess.Busy()))
+	return 1
+}
+
+// send(prompt) sends a prompt, waits and returns the reply.
+func (r *runtime) send(L *lua.LState) int {
+	h := r.check(L, 1)
+	prompt := L.CheckString(2)
+	if err := h.sess.Send(L.Context(), prompt, nil); err != nil {
+		r.fail(L, fmt.Errorf("%s: %w", h.sess.DisplayName(), err))
+	}
+	return r.reply(L)
+}
+
+// submit(prompt) sends a prompt without waiting, so several sessions can
+// work at once; wait collects the reply.
+func (r *runtime) submit(L *lua.LState) int {
+	h := r.check(L, 1)
+	prompt := L.CheckString(2)
+	if h.pending != nil {
+		L.RaiseError("%s: a submitted prompt has not been waited for", h.sess.DisplayName())
+	}
+	done := make(chan error, 1)
+	h.pending = done
+	ctx := L.Context()
+	go func() {
+		done <- h.sess.Send(ctx, prompt, nil)
+	}()
+	return 0
+}
+
+// wait() returns the reply to the prompt passed to submit or, when nothing
+// was submitted, waits for whatever the session is doing to finish.
+func (r *runtime) wait(L *lua.LState) int {
+	r.await(L, r.check(L, 1))
+	return r.reply(L)
+}
+
+// wait(a, b, ...) waits for each session in turn and returns their replies.
+func (r *runtime) waitAll(L *lua.LState) int {
+	n := L.GetTop()
+	for i := 1; i <= n; i++ {
+		h := r.check(L, i)
+		r.await(L, h)
+		reply, _ := h.sess.Select(session.Selection{Kind: session.SelectLastReply})
+		L.Push(lua.LString(reply))
+	}
+	return n
+}
+
+func (r *runtime) await(L *lua.LState, h *handle) {
+	ctx := L.Context()
+	if h.pending != nil {
+		var err error
+		select {
+		case err = <-h.pending:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		h.pending = nil
+		if err != nil {
+			r.fail(L, fmt.Errorf("%s: %w", h.sess.DisplayName(), err))
+		}
+		return
+	}
+
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+	for h.sess.Busy() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			r.fail(L, ctx.Err())
+		}
+	}
+}
+
+// reply() returns the last reply, or nil before the first.
+func (r *runtime) reply(L *lua.LState) int {
+	text, err := r.check(L, 1).sess.Select(session.Selection{Kind: session.SelectLastReply})
+	if err != nil {
+		L.Push(lua.LNil)
+		return 1
+	}
+	L.Push(lua.LString(text))
+	return 1
+}
+
+// last(n) returns the last n prompts and replies, labelled with their role.
+func (r *runtime) last(L *lua.LState) int {
+	return r.selection(L, session.Selection{Kind: session.SelectLastMessages, N: L.CheckInt(2)})
+}
+
+func (r *runtime) transcript(L *lua.LState) int {
+	return r.selection(L, session.Selection{Kind: session.SelectTranscript})
+}
+
+func (r *runtime) selection(L *lua.LState, sel session.Selection) int {
+	if sel.Kind == session.SelectLastMessages && sel.N < 1 {
+		L.ArgError(2, "count must be positive")
+	}
+	text, err := r.check(L, 1).sess.Select(sel)
+	if err != nil {
+		L.Push(lua.LString(""))
+		return 1
+	}
+	L.Push(lua.LString(text))
+	return 1
+}
+
+// pipe(target, {select="last 2", template="..."}) sends part of this
+// session to target as a pipe does and returns target's reply.
+func (r *runtime) pipe(L *lua.LState) int {
+	from := r.check(L, 1)
+	to := r.check(L, 2)
+	p := session.Pipe{From: from.sess, To: to.sess}
+	if opts := L.OptTable(3, nil); opts != nil {
+		sel, err := session.ParseSelection(stringField(L, opts, "select"))
+		if err != nil {
+			r.fail(L, err)
+		}
+		p.Selection = sel
+		p.Template = stringField(L, opts, "template")
+	}
+	if err := p.Send(L.Context(), nil); err != nil {
+		r.fail(L, err)
+	}
+	text, _ := to.sess.Select(session.Selection{Kind: session.SelectLastReply})
+	L.Push(lua.LString(text))
+	return 1
+}
+
+// note(text) adds a system message to the session's transcript.
+func (r *runtime) note(L *lua.LState) int {
+	r.check(L, 1).sess.AddOutput(L.CheckString(2))
+	return 0
+}
+
+func (r *runtime) usage(L *lua.LState) int {
+	usage, cost := r.check(L, 1).sess.GetUsage()
+	t := L.NewTable()
+	t.RawSetString("input_tokens", lua.LNumber(usage.InputTokens))
+	t.RawSetString("output_tokens", lua.LNumber(usage.OutputTokens))
+	t.RawSetString("total_tokens", lua.LNumber(usage.Total()))
+	t.RawSetString("cost", lua.LNumber(cost))
+	L.Push(t)
+	return 1
+}
+
+func (r *runtime) start(L *lua.LState) int {
+	if err := r.check(L, 1).sess.Start(L.Context()); err != nil {
+		r.fail(L, err)
+	}
+	return 0
+}
+
+func (r *runtime) stop(L *lua.LState) int {
+	if err := r.check(L, 1).sess.Stop(L.OptString(2, "stopped by script")); err != nil {
+		r.fail(L, err)
+	}
+	return 0
+}
+
+func (r *runtime) kill(L *lua.LState) int {
+	h := r.check(L, 1)
+	if err := r.env.Manager.KillSession(h.sess.ID, ""); err != nil {
+		r.fail(L, err)
+	}
+	delete(r.handles, h.sess.ID)
+	return 0
+}
+
+func (r *runtime) readScratchpad(L *lua.LState) int {
+	pad, ok := r.env.Manager.GetScratchpad(L.CheckString(1))
+	if !ok {
+		L.Push(lua.LNil)
+		return 1
+	}
+	L.Push(lua.LString(pad.Current().Content))
+	return 1
+}
+
+// scratchpad.write(name, content) saves a new version as the user and
+// returns its number.
+func (r *runtime) writeScratchpad(L *lua.LState) int {
+	version, err := r.env.Manager.WriteScratchpad(L.CheckString(1), L.CheckString(2), nil)
+	if err != nil {
+		r.fail(L, err)
+	}
+	L.Push(lua.LNumber(version.Version))
+	return 1
+}
+
+// sleep(seconds) pauses the script.
+func (r *runtime) sleep(L *lua.LState) int {
+	d := time.Duration(float64(L.CheckNumber(1)) * float64(time.Second))
+	select {
+	case <-time.After(d):
+	case <-L.Context().Done():
+		r.fail(L, L.Context().Err())
+	}
+	return 0
+}
+
+// print writes its arguments to the run's output, separated by tabs.
+func (r *runtime) print(L *lua.LState) int {
+	parts := make([]string, L.GetTop())
+	for i := range parts {
+		parts[i] = L.ToStringMeta(L.Get(i + 1)).String()
+	}
+	fmt.Fprintln(r.env.Output, strings.Join(parts, "\t"))
+	return 0
+}
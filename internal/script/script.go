@@ -0,0 +1,145 @@
+// Package script runs Lua scripts that drive a session manager: they create
+// sessions, send prompts, wait for replies and decide what to do next from
+// what came back, which a static workflow file cannot express.
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"claude-session-manager/internal/session"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// Script is a loaded Lua file. Key is the TUI key it is bound to, declared
+// by a "-- key: f5" line in the comments at the top of the file.
+type Script struct {
+	Name   string
+	Path   string
+	Key    string
+	source string
+}
+
+// Env is what a script runs against. Selected is the session selected in
+// the TUI, nil when run from the command line; print writes to Output.
+type Env struct {
+	Manager  *session.Manager
+	Selected *session.Session
+	Args     []string
+	Output   io.Writer
+}
+
+// Load reads a script and its header.
+func Load(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Script{
+		Name:   strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Path:   path,
+		source: string(data),
+	}
+	for _, line := range strings.Split(s.source, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#!") {
+			continue
+		}
+		comment, ok := strings.CutPrefix(line, "--")
+		if !ok {
+			break
+		}
+		if key, ok := strings.CutPrefix(strings.TrimSpace(comment), "key:"); ok {
+			s.Key = strings.ToLower(strings.TrimSpace(key))
+		}
+	}
+	if s.Key != "" && !Bindable(s.Key) {
+		return nil, fmt.Errorf("%s: cannot bind %q: scripts are bound to f1-f12 or alt+<key>", path, s.Key)
+	}
+	return s, nil
+}
+
+// LoadDir loads every .lua file in dir, sorted by name. A missing directory
+// holds no scripts. Files that fail to load, and keys bound twice, are
+// reported together in the error; the other scripts are still returned.
+func LoadDir(dir string) ([]*Script, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.lua"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var (
+		scripts []*Script
+		errs    []error
+	)
+	bound := make(map[string]string)
+	for _, path := range paths {
+		s, err := Load(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if other, ok := bound[s.Key]; ok && s.Key != "" {
+			errs = append(errs, fmt.Errorf("%s: key %s is already bound to %s", path, s.Key, other))
+			s.Key = ""
+		} else if s.Key != "" {
+			bound[s.Key] = s.Name
+		}
+		scripts = append(scripts, s)
+	}
+	return scripts, errors.Join(errs...)
+}
+
+// Bindable reports whether a script can be bound to key. Only function keys
+// and alt combinations are free of the TUI's own bindings and of typing.
+func Bindable(key string) bool {
+	if rest, ok := strings.CutPrefix(key, "alt+"); ok {
+		return rest != ""
+	}
+	switch key {
+	case "f1", "f2", "f3", "f4", "f5", "f6", "f7", "f8", "f9", "f10", "f11", "f12":
+		return true
+	}
+	return false
+}
+
+// Run runs the script until it returns, fails or ctx is cancelled. Prompts
+// it submitted without waiting for are cancelled when it ends.
+func (s *Script) Run(ctx context.Context, env Env) error {
+	if env.Output == nil {
+		env.Output = io.Discard
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	L := lua.NewState()
+	defer L.Close()
+	L.SetContext(ctx)
+
+	r := &runtime{env: env, handles: make(map[string]*handle)}
+	r.install(L)
+
+	fn, err := L.Load(strings.NewReader(s.source), s.Name)
+	if err != nil {
+		return err
+	}
+	L.Push(fn)
+	if err := L.PCall(0, 0, nil); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var apiErr *lua.ApiError
+		if errors.As(err, &apiErr) {
+			return errors.New(apiErr.Object.String())
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"claude-session-manager/internal/session"
+)
+
+func writeScript(t *testing.T, dir, name, source string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    string
+		wantErr string
+	}{
+		{name: "no header", source: "print(1)\n"},
+		{name: "function key", source: "-- key: F5\nprint(1)\n", want: "f5"},
+		{name: "after a shebang and blank lines", source: "#!/usr/bin/env lua\n\n-- Reviews the diff.\n-- key: alt+r\n", want: "alt+r"},
+		{name: "below the header", source: "print(1)\n-- key: f5\n"},
+		{name: "taken by the TUI", source: "-- key: q\n", wantErr: `cannot bind "q"`},
+		{name: "bare alt", source: "-- key: alt+\n", wantErr: `cannot bind "alt+"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeScript(t, t.TempDir(), "review.lua", tt.source)
+			s, err := Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Name != "review" || s.Key != tt.want {
+				t.Errorf("loaded %q bound to %q, want review bound to %q", s.Name, s.Key, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "a.lua", "-- key: f5\n")
+	writeScript(t, dir, "b.lua", "-- key: f5\n")
+	writeScript(t, dir, "c.lua", "-- key: enter\n")
+	writeScript(t, dir, "d.lua", "-- key: alt+d\n")
+	writeScript(t, dir, "notes.txt", "-- key: f6\n")
+
+	scripts, err := LoadDir(dir)
+	if err == nil || !strings.Contains(err.Error(), "b.lua: key f5 is already bound to a") || !strings.Contains(err.Error(), `cannot bind "enter"`) {
+		t.Fatalf("err = %v, want the duplicate binding and the bad key", err)
+	}
+	var got []string
+	for _, s := range scripts {
+		got = append(got, s.Name+"="+s.Key)
+	}
+	// The second script for a key is kept, but unbound.
+	if want := "a=f5 b= d=alt+d"; strings.Join(got, " ") != want {
+		t.Errorf("scripts = %v, want %s", got, want)
+	}
+
+	scripts, err = LoadDir(filepath.Join(dir, "missing"))
+	if err != nil || len(scripts) != 0 {
+		t.Errorf("missing directory = %d scripts, %v", len(scripts), err)
+	}
+}
+
+func runScript(t *testing.T, ctx context.Context, m *session.Manager, source string) (string, error) {
+	t.Helper()
+	s := &Script{Name: "test", source: source}
+	var out strings.Builder
+	err := s.Run(ctx, Env{Manager: m, Output: &out})
+	return out.String(), err
+}
+
+func TestSubmitAndWait(t *testing.T) {
+	m := session.NewManager()
+	out, err := runScript(t, context.Background(), m, `
+local a = session("a")
+local b = session("b")
+a:submit("one")
+b:submit("two")
+local ra, rb = wait(a, b)
+print(ra)
+print(rb)
+a:submit("three")
+print(a:wait())
+-- With nothing submitted, wait returns the last reply.
+print(b:wait())
+print(a == get(a.id))
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Claude response to: one\nClaude response to: two\nClaude response to: three\nClaude response to: two\ntrue\n"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestSubmitTwice(t *testing.T) {
+	m := session.NewManager()
+	_, err := runScript(t, context.Background(), m, `
+local a = session("a")
+a:submit("one")
+a:submit("two")
+`)
+	if err == nil || !strings.Contains(err.Error(), "a: a submitted prompt has not been waited for") {
+		t.Fatalf("err = %v, want a second submit to fail", err)
+	}
+}
+
+// slowManager's sessions take a second per word to reply.
+func slowManager() *session.Manager {
+	m := session.NewManager()
+	m.SetBackendFactory(func(s *session.Session) session.Backend {
+		backend := session.NewFakeBackend()
+		backend.Delay = time.Second
+		return backend
+	})
+	return m
+}
+
+func waitIdle(t *testing.T, sess *session.Session) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for sess.Busy() {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s is still busy", sess.Name)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRunCancelsPendingSends(t *testing.T) {
+	m := slowManager()
+	// The script ends without waiting for its prompt.
+	if _, err := runScript(t, context.Background(), m, `session("left"):submit("hello")`); err != nil {
+		t.Fatal(err)
+	}
+	sess := m.GetSessions()[0]
+	waitIdle(t, sess)
+	if reply, _ := sess.Select(session.Selection{Kind: session.SelectLastReply}); strings.Contains(reply, "hello") {
+		t.Errorf("the abandoned prompt was answered: %q", reply)
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	m := slowManager()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := runScript(t, ctx, m, `
+local a = session("a")
+a:submit("hello")
+wait(a)
+print("not reached")
+`)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want the deadline", err)
+	}
+	waitIdle(t, m.GetSessions()[0])
+}
@@ -0,0 +1,448 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultAPIBaseURL = "https://api.anthropic.com"
+	DefaultModel      = "claude-sonnet-4-20250514"
+	DefaultMaxTokens  = 4096
+
+	anthropicVersion = "2023-06-01"
+
+	// maxToolRounds bounds how many times one turn goes back to the API with
+	// tool results.
+	maxToolRounds = 20
+)
+
+// APIBackend talks to the Anthropic Messages API and streams the reply over
+// server-sent events.
+type APIBackend struct {
+	APIKey    string
+	BaseURL   string
+	Model     string
+	MaxTokens int
+	Client    *http.Client
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	closed bool
+}
+
+func NewAPIBackend(apiKey string) *APIBackend {
+	return &APIBackend{
+		APIKey:    apiKey,
+		BaseURL:   DefaultAPIBaseURL,
+		Model:     DefaultModel,
+		MaxTokens: DefaultMaxTokens,
+		Client:    http.DefaultClient,
+	}
+}
+
+// APIError is an error returned by the API, either as an HTTP error response
+// or as an error event in the middle of a stream.
+type APIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("api error %d (%s): %s", e.StatusCode, e.Type, e.Message)
+	}
+	return fmt.Sprintf("api error (%s): %s", e.Type, e.Message)
+}
+
+// apiMessage content is a string for plain text and []apiContent once tool
+// calls and results are involved.
+type apiMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+type apiContent struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+}
+
+type apiTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+type apiRequest struct {
+	Model       string       `json:"model"`
+	MaxTokens   int          `json:"max_tokens"`
+	System      string       `json:"system,omitempty"`
+	Temperature *float64     `json:"temperature,omitempty"`
+	Messages    []apiMessage `json:"messages"`
+	Tools       []apiTool    `json:"tools,omitempty"`
+	Stream      bool         `json:"stream"`
+}
+
+// apiTurn is what one streamed response said, kept so tool calls can be
+// answered in the next request.
+type apiTurn struct {
+	content    []apiContent
+	stopReason string
+}
+
+type apiErrorBody struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type apiUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+}
+
+type apiStreamEvent struct {
+	Type    string `json:"type"`
+	Index   int    `json:"index"`
+	Message *struct {
+		Usage apiUsage `json:"usage"`
+	} `json:"message"`
+	ContentBlock *struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"content_block"`
+	Delta *struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *apiUsage     `json:"usage"`
+	Error *apiErrorBody `json:"error"`
+}
+
+func (b *APIBackend) Send(ctx context.Context, req Request, emit func(Chunk)) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBackendClosed
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	b.cancel = cancel
+	b.mu.Unlock()
+	defer cancel()
+
+	body := b.newAPIRequest(req)
+	for round := 1; ; round++ {
+		turn, err := b.post(ctx, body, emit)
+		if err != nil {
+			return err
+		}
+		if turn.stopReason != "tool_use" || len(req.Tools) == 0 {
+			return nil
+		}
+		if round >= maxToolRounds {
+			return fmt.Errorf("gave up after %d rounds of tool calls", maxToolRounds)
+		}
+		body.Messages = append(body.Messages,
+			apiMessage{Role: string(RoleAssistant), Content: turn.content},
+			apiMessage{Role: string(RoleUser), Content: runTools(req.Tools, turn.content, emit)},
+		)
+	}
+}
+
+// post sends one request and streams its response.
+func (b *APIBackend) post(ctx context.Context, request apiRequest, emit func(Chunk)) (apiTurn, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return apiTurn{}, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(b.BaseURL, "/")+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return apiTurn{}, err
+	}
+	httpReq.Header.Set("content-type", "application/json")
+	httpReq.Header.Set("accept", "text/event-stream")
+	httpReq.Header.Set("x-api-key", b.APIKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+
+	client := b.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return apiTurn{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return apiTurn{}, newAPIError(resp)
+	}
+
+	return readMessageStream(resp.Body, emit)
+}
+
+// runTools answers the tool calls in content, emitting each result.
+func runTools(tools []Tool, content []apiContent, emit func(Chunk)) []apiContent {
+	var results []apiContent
+	for _, block := range content {
+		if block.Type != "tool_use" {
+			continue
+		}
+		result := apiContent{Type: "tool_result", ToolUseID: block.ID}
+		output, err := callTool(tools, block.Name, block.Input)
+		if err != nil {
+			result.Content, result.IsError = err.Error(), true
+		} else {
+			result.Content = output
+		}
+		emit(Chunk{Type: ChunkToolResult, ToolID: block.ID, Text: result.Content, IsError: result.IsError})
+		results = append(results, result)
+	}
+	return results
+}
+
+// newAPIRequest builds the request body, preferring the session's settings
+// over the backend's defaults.
+func (b *APIBackend) newAPIRequest(req Request) apiRequest {
+	body := apiRequest{
+		Model:       b.Model,
+		MaxTokens:   b.MaxTokens,
+		System:      req.System,
+		Temperature: req.Temperature,
+		Stream:      true,
+	}
+	if req.Model != "" {
+		body.Model = req.Model
+	}
+	if req.MaxTokens > 0 {
+		body.MaxTokens = req.MaxTokens
+	}
+
+	messages := req.Messages
+	if len(messages) == 0 {
+		messages = []Message{{Role: RoleUser, Content: req.Input}}
+	}
+	for _, msg := range messages {
+		body.Messages = append(body.Messages, apiMessage{Role: string(msg.Role), Content: msg.Content})
+	}
+	for _, tool := range req.Tools {
+		body.Tools = append(body.Tools, apiTool{Name: tool.Name, Description: tool.Description, InputSchema: tool.InputSchema})
+	}
+	return body
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Type:       http.StatusText(resp.StatusCode),
+		RetryAfter: parseRetryAfter(resp.Header.Get("retry-after")),
+	}
+
+	var body struct {
+		Error apiErrorBody `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxStderrBytes))
+	if err := json.Unmarshal(data, &body); err == nil && body.Error.Type != "" {
+		apiErr.Type = body.Error.Type
+		apiErr.Message = body.Error.Message
+	} else {
+		apiErr.Message = strings.TrimSpace(string(data))
+	}
+	return apiErr
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+// readMessageStream consumes a Messages API event stream, emitting chunks as
+// they arrive, and returns the response's content blocks.
+func readMessageStream(r io.Reader, emit func(Chunk)) (apiTurn, error) {
+	var (
+		turn      apiTurn
+		usage     Usage
+		blocks    = map[int]*apiContent{}
+		order     []int
+		toolInput = map[int]*strings.Builder{}
+	)
+
+	err := readSSE(r, func(eventType string, data []byte) (bool, error) {
+		var event apiStreamEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return false, fmt.Errorf("decoding %s event: %w", eventType, err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				u := event.Message.Usage
+				usage.InputTokens = u.InputTokens
+				usage.OutputTokens = u.OutputTokens
+				usage.CacheReadTokens = u.CacheReadInputTokens
+				usage.CacheWriteTokens = u.CacheCreationInputTokens
+			}
+
+		case "content_block_start":
+			if event.ContentBlock == nil {
+				break
+			}
+			blocks[event.Index] = &apiContent{Type: event.ContentBlock.Type, ID: event.ContentBlock.ID, Name: event.ContentBlock.Name}
+			order = append(order, event.Index)
+			if event.ContentBlock.Type == "tool_use" {
+				toolInput[event.Index] = &strings.Builder{}
+			}
+
+		case "content_block_delta":
+			if event.Delta == nil {
+				break
+			}
+			switch event.Delta.Type {
+			case "text_delta":
+				if block, ok := blocks[event.Index]; ok {
+					block.Text += event.Delta.Text
+				}
+				emit(Chunk{Type: ChunkText, Text: event.Delta.Text})
+			case "input_json_delta":
+				if input, ok := toolInput[event.Index]; ok {
+					input.WriteString(event.Delta.PartialJSON)
+				}
+			}
+
+		case "content_block_stop":
+			if input, ok := toolInput[event.Index]; ok {
+				block := blocks[event.Index]
+				block.Input = json.RawMessage(input.String())
+				if len(block.Input) == 0 {
+					block.Input = json.RawMessage("{}")
+				}
+				emit(Chunk{Type: ChunkToolUse, ToolID: block.ID, ToolName: block.Name, Text: input.String()})
+				delete(toolInput, event.Index)
+			}
+
+		case "message_delta":
+			if event.Usage != nil {
+				usage.OutputTokens = event.Usage.OutputTokens
+			}
+			if event.Delta != nil && event.Delta.StopReason != "" {
+				turn.stopReason = event.Delta.StopReason
+			}
+
+		case "message_stop":
+			emit(Chunk{Type: ChunkUsage, Usage: usage})
+			return true, nil
+
+		case "error":
+			apiErr := &APIError{Type: "error"}
+			if event.Error != nil {
+				apiErr.Type = event.Error.Type
+				apiErr.Message = event.Error.Message
+			}
+			return false, apiErr
+		}
+		return false, nil
+	})
+
+	for _, index := range order {
+		if block := blocks[index]; block.Type != "text" || block.Text != "" {
+			turn.content = append(turn.content, *block)
+		}
+	}
+	return turn, err
+}
+
+// readSSE splits a server-sent event stream into events and hands each one to
+// handle until it reports done. A stream that ends before that is an error.
+func readSSE(r io.Reader, handle func(eventType string, data []byte) (bool, error)) error {
+	reader := bufio.NewReader(r)
+	var (
+		eventType string
+		data      bytes.Buffer
+	)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		trimmed := strings.TrimRight(line, "\r\n")
+
+		switch {
+		case trimmed == "" && line != "":
+			if data.Len() > 0 {
+				done, handleErr := handle(eventType, data.Bytes())
+				if handleErr != nil || done {
+					return handleErr
+				}
+			}
+			eventType = ""
+			data.Reset()
+		case strings.HasPrefix(trimmed, ":"):
+			// Comment line.
+		case strings.HasPrefix(trimmed, "event:"):
+			eventType = strings.TrimSpace(strings.TrimPrefix(trimmed, "event:"))
+		case strings.HasPrefix(trimmed, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(trimmed, "data:"), " "))
+		}
+
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+	}
+}
+
+func (b *APIBackend) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.cancel != nil {
+		b.cancel()
+	}
+}
+
+func (b *APIBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	if b.cancel != nil {
+		b.cancel()
+	}
+	return nil
+}
+
+func (b *APIBackend) Capabilities() Capabilities {
+	return Capabilities{Streaming: true, Cancel: true, Tools: true, ClientTools: true}
+}
@@ -0,0 +1,192 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// replayServer answers every request with the recorded event stream in
+// testdata/api/name.
+func replayServer(t *testing.T, name string) *httptest.Server {
+	t.Helper()
+	stream, err := os.ReadFile(filepath.Join("testdata", "api", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" || r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") != anthropicVersion {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("content-type", "text/event-stream")
+		w.Write(stream)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func sendAPI(t *testing.T, baseURL string) ([]Chunk, error) {
+	t.Helper()
+	backend := NewAPIBackend("test-key")
+	backend.BaseURL = baseURL
+	var chunks []Chunk
+	err := backend.Send(context.Background(), Request{Input: "Hi"}, func(chunk Chunk) {
+		chunks = append(chunks, chunk)
+	})
+	return chunks, err
+}
+
+func TestAPIBackendStreamsText(t *testing.T) {
+	chunks, err := sendAPI(t, replayServer(t, "text.sse").URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var texts []string
+	var usage *Usage
+	for _, chunk := range chunks {
+		switch chunk.Type {
+		case ChunkText:
+			texts = append(texts, chunk.Text)
+		case ChunkUsage:
+			usage = &chunk.Usage
+		}
+	}
+	want := []string{"Hello", ", ", "world"}
+	if len(texts) != len(want) {
+		t.Fatalf("text chunks = %q, want %q", texts, want)
+	}
+	for i := range want {
+		if texts[i] != want[i] {
+			t.Errorf("text chunk %d = %q, want %q", i, texts[i], want[i])
+		}
+	}
+
+	// message_delta carries the final output token count.
+	wantUsage := Usage{InputTokens: 12, OutputTokens: 6, CacheReadTokens: 4, CacheWriteTokens: 2}
+	if usage == nil || *usage != wantUsage {
+		t.Errorf("usage = %+v, want %+v", usage, wantUsage)
+	}
+	if last := chunks[len(chunks)-1]; last.Type != ChunkUsage {
+		t.Errorf("last chunk is %v, want the usage", last.Type)
+	}
+}
+
+func TestAPIBackendErrorEvent(t *testing.T) {
+	chunks, err := sendAPI(t, replayServer(t, "error.sse").URL)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want an *APIError", err)
+	}
+	if apiErr.StatusCode != 0 || apiErr.Type != "overloaded_error" || apiErr.Message != "Overloaded" {
+		t.Errorf("err = %+v, want an overloaded_error event", apiErr)
+	}
+	if len(chunks) != 1 || chunks[0].Text != "Partial" {
+		t.Errorf("chunks = %+v, want the text before the error", chunks)
+	}
+	if sendErr := classifyError(err); sendErr.Kind != ErrorOverloaded || !sendErr.Retryable {
+		t.Errorf("classified as %s (retryable %v), want a retryable %s", sendErr.Kind, sendErr.Retryable, ErrorOverloaded)
+	}
+}
+
+func TestAPIBackendTruncatedStream(t *testing.T) {
+	_, err := sendAPI(t, replayServer(t, "truncated.sse").URL)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("err = %v, want io.ErrUnexpectedEOF", err)
+	}
+	if sendErr := classifyError(err); sendErr.Kind != ErrorNetwork || !sendErr.Retryable {
+		t.Errorf("classified as %s (retryable %v), want a retryable %s", sendErr.Kind, sendErr.Retryable, ErrorNetwork)
+	}
+}
+
+func TestAPIBackendHTTPErrors(t *testing.T) {
+	retryAt := time.Now().Add(90 * time.Second).UTC().Format(http.TimeFormat)
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		body       string
+		wantType   string
+		wantKind   ErrorKind
+		wantAfter  time.Duration
+	}{
+		{
+			name:       "rate limited",
+			status:     http.StatusTooManyRequests,
+			retryAfter: "7",
+			body:       `{"type":"error","error":{"type":"rate_limit_error","message":"Slow down"}}`,
+			wantType:   "rate_limit_error",
+			wantKind:   ErrorRateLimited,
+			wantAfter:  7 * time.Second,
+		},
+		{
+			name:       "overloaded with a date",
+			status:     529,
+			retryAfter: retryAt,
+			body:       `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			wantType:   "overloaded_error",
+			wantKind:   ErrorOverloaded,
+			wantAfter:  90 * time.Second,
+		},
+		{
+			name:     "overloaded without a body",
+			status:   529,
+			body:     "upstream busy",
+			wantType: http.StatusText(529),
+			wantKind: ErrorOverloaded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("retry-after", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			_, err := sendAPI(t, server.URL)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want an *APIError", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Type != tt.wantType {
+				t.Errorf("err = %+v, want status %d and type %q", apiErr, tt.status, tt.wantType)
+			}
+			// A date leaves a little slack for the time the request took.
+			if diff := tt.wantAfter - apiErr.RetryAfter; diff < 0 || diff > 2*time.Second {
+				t.Errorf("retry after %s, want %s", apiErr.RetryAfter, tt.wantAfter)
+			}
+			if sendErr := classifyError(err); sendErr.Kind != tt.wantKind || !sendErr.Retryable {
+				t.Errorf("classified as %s (retryable %v), want a retryable %s", sendErr.Kind, sendErr.Retryable, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"1.5", 1500 * time.Millisecond},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
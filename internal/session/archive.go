@@ -0,0 +1,52 @@
+package session
+
+// Archiver keeps sessions beyond the life of the process. The Manager hands
+// it a session's final state when the session is removed or the Manager is
+// closed.
+type Archiver interface {
+	Archive(state SessionState) error
+}
+
+func (m *Manager) SetArchiver(archiver Archiver) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.archiver = archiver
+}
+
+func (m *Manager) archive(session *Session) error {
+	m.mu.RLock()
+	archiver := m.archiver
+	m.mu.RUnlock()
+
+	if archiver == nil {
+		return nil
+	}
+	return archiver.Archive(session.State())
+}
+
+// ArchiveAll hands every open session to the archiver.
+func (m *Manager) ArchiveAll() error {
+	var firstErr error
+	for _, session := range m.GetSessions() {
+		if err := m.archive(session); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close archives every session and closes their journals cleanly. Call it
+// when the program exits normally.
+func (m *Manager) Close() error {
+	firstErr := m.ArchiveAll()
+	for _, session := range m.GetSessions() {
+		session.mu.Lock()
+		journal := session.journal
+		session.journal = nil
+		session.mu.Unlock()
+		if err := journal.close(true); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
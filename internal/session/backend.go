@@ -0,0 +1,102 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var ErrBackendClosed = errors.New("backend closed")
+
+type ChunkType int
+
+const (
+	ChunkText ChunkType = iota
+	ChunkToolUse
+	ChunkToolResult
+	ChunkUsage
+	ChunkSessionID
+)
+
+// Chunk is a single piece of streamed backend output. Text carries assistant
+// text, tool input (ChunkToolUse), tool output (ChunkToolResult) or the ID a
+// backend that keeps its own conversations gave this one (ChunkSessionID).
+type Chunk struct {
+	Type     ChunkType
+	Text     string
+	ToolID   string
+	ToolName string
+	IsError  bool
+	Usage    Usage
+}
+
+type Usage struct {
+	InputTokens      int `json:"input_tokens"`
+	OutputTokens     int `json:"output_tokens"`
+	CacheReadTokens  int `json:"cache_read_tokens"`
+	CacheWriteTokens int `json:"cache_write_tokens"`
+}
+
+func (u *Usage) Add(other Usage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CacheReadTokens += other.CacheReadTokens
+	u.CacheWriteTokens += other.CacheWriteTokens
+}
+
+func (u Usage) Total() int {
+	return u.InputTokens + u.OutputTokens + u.CacheReadTokens + u.CacheWriteTokens
+}
+
+// Request is one turn sent to a backend. Input is the new prompt; Messages is
+// the full conversation ending with that prompt, for backends that are not
+// stateful themselves.
+type Request struct {
+	Input       string
+	Messages    []Message
+	System      string
+	Model       string
+	MaxTokens   int
+	Temperature *float64
+	Tools       []Tool
+}
+
+// Tool is a function the model may call during a turn. Run gets the call's
+// JSON input and returns the text sent back as the tool result.
+type Tool struct {
+	Name        string
+	Description string
+	InputSchema json.RawMessage
+	Run         func(input json.RawMessage) (string, error)
+}
+
+// Capabilities describes a backend. Tools means replies can include tool
+// calls; ClientTools means the backend also runs the Tools of a Request.
+type Capabilities struct {
+	Streaming   bool
+	Cancel      bool
+	Tools       bool
+	ClientTools bool
+}
+
+// Backend is the transport a Session uses to talk to Claude. Send blocks until
+// the reply is complete, calling emit for every chunk as it arrives.
+type Backend interface {
+	Send(ctx context.Context, req Request, emit func(Chunk)) error
+	Cancel()
+	Close() error
+	Capabilities() Capabilities
+}
+
+type BackendFactory func(s *Session) Backend
+
+// callTool runs the tool called name.
+func callTool(tools []Tool, name string, input json.RawMessage) (string, error) {
+	for _, tool := range tools {
+		if tool.Name == name {
+			return tool.Run(input)
+		}
+	}
+	return "", fmt.Errorf("unknown tool %q", name)
+}
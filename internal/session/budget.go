@@ -0,0 +1,139 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// Budget limits what a session, or all sessions together, may spend. Crossing
+// a soft limit raises a warning; crossing a hard limit stops the work. Zero
+// means no limit.
+type Budget struct {
+	SoftTokens int     `json:"soft_tokens,omitempty"`
+	HardTokens int     `json:"hard_tokens,omitempty"`
+	SoftCost   float64 `json:"soft_cost,omitempty"`
+	HardCost   float64 `json:"hard_cost,omitempty"`
+}
+
+type BudgetState int
+
+const (
+	BudgetOK BudgetState = iota
+	BudgetSoftExceeded
+	BudgetHardExceeded
+)
+
+// Check compares usage against the budget and returns the state along with a
+// human readable reason when a limit has been reached.
+func (b Budget) Check(usage Usage, cost float64) (BudgetState, string) {
+	tokens := usage.Total()
+	switch {
+	case b.HardTokens > 0 && tokens >= b.HardTokens:
+		return BudgetHardExceeded, fmt.Sprintf("hard token limit reached (%d/%d)", tokens, b.HardTokens)
+	case b.HardCost > 0 && cost >= b.HardCost:
+		return BudgetHardExceeded, fmt.Sprintf("hard cost limit reached ($%.4f/$%.2f)", cost, b.HardCost)
+	case b.SoftTokens > 0 && tokens >= b.SoftTokens:
+		return BudgetSoftExceeded, fmt.Sprintf("soft token limit reached (%d/%d)", tokens, b.SoftTokens)
+	case b.SoftCost > 0 && cost >= b.SoftCost:
+		return BudgetSoftExceeded, fmt.Sprintf("soft cost limit reached ($%.4f/$%.2f)", cost, b.SoftCost)
+	}
+	return BudgetOK, ""
+}
+
+// checkBudget applies the session's own budget after its usage changed.
+func (s *Session) checkBudget() {
+	usage, cost := s.GetUsage()
+	state, reason := s.GetConfig().Budget.Check(usage, cost)
+
+	switch state {
+	case BudgetSoftExceeded:
+		s.warnBudget(reason)
+	case BudgetHardExceeded:
+		s.halt("budget: " + reason)
+	}
+}
+
+// warnBudget records a budget warning, noting it in the transcript the first
+// time it is raised.
+func (s *Session) warnBudget(reason string) {
+	s.mu.Lock()
+	first := s.BudgetWarning == ""
+	s.BudgetWarning = reason
+	s.mu.Unlock()
+
+	if first {
+		s.AddOutput("⚠ Budget warning: " + reason)
+	}
+}
+
+func (s *Session) GetBudgetWarning() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.BudgetWarning
+}
+
+func (s *Session) GetStopReason() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.StopReason
+}
+
+// halt cancels any in-flight request and moves the session to StatusStopped,
+// recording why.
+func (s *Session) halt(reason string) {
+	s.mu.Lock()
+	if s.Status == StatusKilled || (s.Status == StatusStopped && s.StopReason == reason) {
+		s.mu.Unlock()
+		return
+	}
+	s.StopReason = reason
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	if err := s.SetStatus(StatusStopped); err == nil {
+		s.AddOutput("■ Stopped: " + reason)
+	}
+}
+
+// SetBudget sets the limit on all sessions' combined usage.
+func (m *Manager) SetBudget(budget Budget) {
+	m.mu.Lock()
+	m.budget = budget
+	m.mu.Unlock()
+
+	m.checkBudget()
+}
+
+// BudgetStatus reports the state of the global budget.
+func (m *Manager) BudgetStatus() (BudgetState, string) {
+	m.mu.RLock()
+	budget := m.budget
+	m.mu.RUnlock()
+
+	usage, cost := m.TotalUsage()
+	return budget.Check(usage, cost)
+}
+
+// checkBudget stops every session once the global hard limit is reached.
+func (m *Manager) checkBudget() {
+	state, reason := m.BudgetStatus()
+	if state != BudgetHardExceeded {
+		return
+	}
+	for _, session := range m.GetSessions() {
+		session.halt("global budget: " + reason)
+	}
+}
+
+// admit refuses new requests once the global hard limit is reached.
+func (m *Manager) admit() error {
+	if state, reason := m.BudgetStatus(); state == BudgetHardExceeded {
+		return fmt.Errorf("%w: global %s", ErrBudgetExceeded, reason)
+	}
+	return nil
+}
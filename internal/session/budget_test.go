@@ -0,0 +1,153 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// meteredBackend answers every request with 20 tokens of usage.
+type meteredBackend struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (b *meteredBackend) Send(ctx context.Context, req Request, emit func(Chunk)) error {
+	b.mu.Lock()
+	b.calls++
+	b.mu.Unlock()
+	emit(Chunk{Type: ChunkText, Text: "ok"})
+	emit(Chunk{Type: ChunkUsage, Usage: Usage{InputTokens: 10, OutputTokens: 10}})
+	return nil
+}
+
+func (b *meteredBackend) Cancel()                    {}
+func (b *meteredBackend) Close() error               { return nil }
+func (b *meteredBackend) Capabilities() Capabilities { return Capabilities{} }
+
+func (b *meteredBackend) sent() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls
+}
+
+func meteredManager() (*Manager, *meteredBackend) {
+	backend := &meteredBackend{}
+	m := NewManager()
+	m.SetBackendFactory(func(*Session) Backend { return backend })
+	return m, backend
+}
+
+func budgetWarnings(s *Session) int {
+	n := 0
+	for _, entry := range s.GetTranscript() {
+		if strings.HasPrefix(entry.Text(), "⚠ Budget warning") {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSessionBudget(t *testing.T) {
+	m, backend := meteredManager()
+	config := m.DefaultConfig()
+	config.Budget = Budget{SoftTokens: 30, HardTokens: 60}
+	sess := m.CreateSession("metered", config)
+	ctx := context.Background()
+
+	if err := sess.Send(ctx, "one", nil); err != nil {
+		t.Fatal(err)
+	}
+	if warning := sess.GetBudgetWarning(); warning != "" {
+		t.Errorf("warned at 20 tokens: %s", warning)
+	}
+
+	// Crossing the soft limit warns, once, and carries on.
+	if err := sess.Send(ctx, "two", nil); err != nil {
+		t.Fatal(err)
+	}
+	if warning := sess.GetBudgetWarning(); warning != "soft token limit reached (40/30)" {
+		t.Errorf("warning at 40 tokens = %q", warning)
+	}
+	if err := sess.Send(ctx, "three", nil); !errors.Is(err, ErrStopped) {
+		t.Fatalf("send reaching the hard limit = %v, want the session stopped", err)
+	}
+	if n := budgetWarnings(sess); n != 1 {
+		t.Errorf("got %d budget warnings in the transcript, want 1", n)
+	}
+
+	// The hard limit stops the session and refuses further prompts.
+	if sess.GetStatus() != StatusStopped || sess.GetStopReason() != "budget: hard token limit reached (60/60)" {
+		t.Errorf("status %s (%s), want stopped by the budget", sess.GetStatus(), sess.GetStopReason())
+	}
+	if err := sess.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := sess.Send(ctx, "four", nil); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("send after the hard limit = %v, want ErrBudgetExceeded", err)
+	}
+	if backend.sent() != 3 {
+		t.Errorf("backend got %d requests, want 3", backend.sent())
+	}
+}
+
+func TestManagerBudget(t *testing.T) {
+	m, backend := meteredManager()
+	m.SetBudget(Budget{SoftTokens: 30, HardTokens: 50})
+	a := m.CreateSession("a", m.DefaultConfig())
+	b := m.CreateSession("b", m.DefaultConfig())
+	ctx := context.Background()
+
+	for _, sess := range []*Session{a, b} {
+		if err := sess.Send(ctx, "hi", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state, reason := m.BudgetStatus(); state != BudgetSoftExceeded || reason != "soft token limit reached (40/30)" {
+		t.Errorf("budget status %d (%s), want the soft limit", state, reason)
+	}
+	if err := m.admit(); err != nil {
+		t.Errorf("admit over the soft limit = %v", err)
+	}
+
+	// One session crossing the hard limit stops them all.
+	a.Send(ctx, "again", nil)
+	for _, sess := range []*Session{a, b} {
+		if sess.GetStatus() != StatusStopped || !strings.HasPrefix(sess.GetStopReason(), "global budget: hard token limit") {
+			t.Errorf("%s is %s (%s), want stopped by the global budget", sess.Name, sess.GetStatus(), sess.GetStopReason())
+		}
+	}
+
+	if err := m.admit(); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("admit over the hard limit = %v", err)
+	}
+	if err := b.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Send(ctx, "more", nil); !errors.Is(err, ErrBudgetExceeded) || !strings.Contains(err.Error(), "global") {
+		t.Errorf("send over the global hard limit = %v, want ErrBudgetExceeded", err)
+	}
+	if backend.sent() != 3 {
+		t.Errorf("backend got %d requests, want 3", backend.sent())
+	}
+}
+
+func TestBudgetOmittedWhenUnset(t *testing.T) {
+	data, err := json.Marshal(SessionConfig{Model: "opus"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "budget") {
+		t.Errorf("config without a budget marshals to %s", data)
+	}
+	data, err = json.Marshal(SessionConfig{Budget: Budget{HardCost: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"budget":{"hard_cost":2}`) {
+		t.Errorf("config with a budget marshals to %s", data)
+	}
+}
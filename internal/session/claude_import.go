@@ -0,0 +1,312 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrEmptyClaudeLog is returned for logs with no messages, such as those
+// Claude Code writes holding only a summary.
+var ErrEmptyClaudeLog = errors.New("no conversation in Claude Code log")
+
+// ClaudeProjectsDir is where Claude Code keeps its per-project conversation
+// logs: $CLAUDE_CONFIG_DIR/projects, or ~/.claude/projects.
+func ClaudeProjectsDir() (string, error) {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "projects"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".claude", "projects"), nil
+}
+
+// FindClaudeLogs lists the conversation logs under a Claude Code projects
+// directory, one directory per project.
+func FindClaudeLogs(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// claudeLogLine is one record of a Claude Code conversation log. Messages
+// share their shape with the CLI's stream-json events, except that user
+// content may be a plain string.
+type claudeLogLine struct {
+	Type        string    `json:"type"`
+	SessionID   string    `json:"sessionId"`
+	Cwd         string    `json:"cwd"`
+	Timestamp   time.Time `json:"timestamp"`
+	IsSidechain bool      `json:"isSidechain"`
+	IsMeta      bool      `json:"isMeta"`
+	Summary     string    `json:"summary"`
+	Content     string    `json:"content"`
+	Message     *struct {
+		ID      string          `json:"id"`
+		Role    string          `json:"role"`
+		Model   string          `json:"model"`
+		Content json.RawMessage `json:"content"`
+		Usage   *cliUsage       `json:"usage"`
+	} `json:"message"`
+}
+
+// ReadClaudeLog converts a Claude Code conversation log into a stopped
+// session whose config resumes the conversation through the cli backend.
+func ReadClaudeLog(path string) (SessionState, error) {
+	return readClaudeLog(path, DefaultPriceTable())
+}
+
+func readClaudeLog(path string, prices PriceTable) (SessionState, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return SessionState{}, err
+	}
+	defer file.Close()
+
+	state := SessionState{
+		ID:     generateID(),
+		Status: StatusStopped,
+		Config: SessionConfig{ResumeID: strings.TrimSuffix(filepath.Base(path), ".jsonl")},
+	}
+	var (
+		summary string
+		// Claude Code logs a reply one content block per line, each line
+		// repeating the message ID and its usage so far.
+		messageEntry = make(map[string]int)
+		messageUsage = make(map[string]Usage)
+		messageModel = make(map[string]string)
+	)
+
+	reader := bufio.NewReader(file)
+	for {
+		raw, readErr := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(raw)) > 0 {
+			var line claudeLogLine
+			if json.Unmarshal(raw, &line) == nil && !line.IsSidechain && !line.IsMeta {
+				if line.SessionID != "" {
+					state.Config.ResumeID = line.SessionID
+				}
+				if line.Cwd != "" {
+					state.Config.WorkDir = line.Cwd
+				}
+				if !line.Timestamp.IsZero() {
+					if state.CreatedAt.IsZero() {
+						state.CreatedAt = line.Timestamp
+					}
+					state.UpdatedAt = line.Timestamp
+				}
+
+				switch line.Type {
+				case "summary":
+					summary = line.Summary
+				case "system":
+					if line.Content != "" {
+						state.Transcript = append(state.Transcript, claudeEntry(RoleSystem, line.Timestamp, ContentBlock{Type: BlockText, Text: line.Content}))
+					}
+				case "user":
+					if line.Message != nil {
+						state.Transcript = append(state.Transcript, claudeUserEntries(line.Message.Content, line.Timestamp)...)
+					}
+				case "assistant":
+					if line.Message == nil {
+						break
+					}
+					blocks := claudeAssistantBlocks(line.Message.Content)
+					id := line.Message.ID
+					if i, ok := messageEntry[id]; ok && id != "" && i == len(state.Transcript)-1 {
+						state.Transcript[i].Blocks = append(state.Transcript[i].Blocks, blocks...)
+					} else if len(blocks) > 0 {
+						state.Transcript = append(state.Transcript, claudeEntry(RoleAssistant, line.Timestamp, blocks...))
+						messageEntry[id] = len(state.Transcript) - 1
+					}
+					if line.Message.Model != "" {
+						state.Config.Model = line.Message.Model
+						messageModel[id] = line.Message.Model
+					}
+					// Usage is kept per message; a line without an ID cannot
+					// be told apart from the others, so its usage is left out.
+					if id != "" && line.Message.Usage != nil {
+						messageUsage[id] = line.Message.Usage.toUsage()
+					}
+				}
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return state, readErr
+		}
+	}
+
+	for id, usage := range messageUsage {
+		state.Usage.Add(usage)
+		state.Cost += prices.Cost(messageModel[id], usage)
+		if i, ok := messageEntry[id]; ok {
+			usage := usage
+			state.Transcript[i].Usage = &usage
+		}
+	}
+	for i := range state.Transcript {
+		state.Transcript[i].SessionID = state.ID
+		if state.Transcript[i].Role == RoleUser {
+			state.LastPrompt = state.Transcript[i].Text()
+		}
+	}
+
+	if len(state.Transcript) == 0 {
+		return state, fmt.Errorf("%w: %s", ErrEmptyClaudeLog, path)
+	}
+	state.Name = claudeSessionName(summary, state)
+	state.StopReason = "imported from Claude Code"
+	return state, nil
+}
+
+func claudeEntry(role Role, timestamp time.Time, blocks ...ContentBlock) Entry {
+	return Entry{Role: role, Blocks: blocks, Timestamp: timestamp}
+}
+
+// claudeUserEntries splits user content into the prompt text and one tool
+// entry per tool result.
+func claudeUserEntries(raw json.RawMessage, timestamp time.Time) []Entry {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		if text == "" {
+			return nil
+		}
+		return []Entry{claudeEntry(RoleUser, timestamp, ContentBlock{Type: BlockText, Text: text})}
+	}
+
+	var content []cliContent
+	if json.Unmarshal(raw, &content) != nil {
+		return nil
+	}
+	var (
+		entries []Entry
+		prompt  []ContentBlock
+	)
+	for _, block := range content {
+		switch block.Type {
+		case "text":
+			prompt = append(prompt, ContentBlock{Type: BlockText, Text: block.Text})
+		case "tool_result":
+			entries = append(entries, claudeEntry(RoleTool, timestamp, ContentBlock{
+				Type:    BlockToolResult,
+				Text:    toolResultText(block.Content),
+				ToolID:  block.ToolUseID,
+				IsError: block.IsError,
+			}))
+		}
+	}
+	if len(prompt) > 0 {
+		entries = append([]Entry{claudeEntry(RoleUser, timestamp, prompt...)}, entries...)
+	}
+	return entries
+}
+
+func claudeAssistantBlocks(raw json.RawMessage) []ContentBlock {
+	var content []cliContent
+	if json.Unmarshal(raw, &content) != nil {
+		return nil
+	}
+	var blocks []ContentBlock
+	for _, block := range content {
+		switch block.Type {
+		case "text":
+			blocks = append(blocks, ContentBlock{Type: BlockText, Text: block.Text})
+		case "tool_use":
+			blocks = append(blocks, ContentBlock{Type: BlockToolUse, Text: string(block.Input), ToolID: block.ID, ToolName: block.Name})
+		}
+	}
+	return blocks
+}
+
+// claudeSessionName prefers Claude Code's own summary of the conversation,
+// then its first prompt, prefixed with the project directory.
+func claudeSessionName(summary string, state SessionState) string {
+	name := summary
+	if name == "" {
+		for _, entry := range state.Transcript {
+			if entry.Role == RoleUser {
+				name = strings.Join(strings.Fields(entry.Text()), " ")
+				break
+			}
+		}
+	}
+	if runes := []rune(name); len(runes) > 40 {
+		name = string(runes[:37]) + "..."
+	}
+	if state.Config.WorkDir != "" {
+		name = filepath.Base(state.Config.WorkDir) + ": " + name
+	}
+	return name
+}
+
+// ImportClaudeLog adds the conversation in a Claude Code log as a stopped
+// session. Starting it continues the conversation with the cli backend. A
+// conversation that is already open is returned as is.
+func (m *Manager) ImportClaudeLog(path string) (*Session, error) {
+	m.mu.RLock()
+	prices := m.prices
+	m.mu.RUnlock()
+
+	state, err := readClaudeLog(path, prices)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range m.GetSessions() {
+		if session.GetConfig().ResumeID == state.Config.ResumeID {
+			return session, nil
+		}
+	}
+	return m.restoreState(state, state.StopReason, nil), nil
+}
+
+// ImportClaudeLogs imports every conversation under a Claude Code projects
+// directory that is not open yet. Logs that cannot be read are skipped; the
+// first such error is returned along with the sessions that were added.
+func (m *Manager) ImportClaudeLogs(dir string) ([]*Session, error) {
+	paths, err := FindClaudeLogs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	open := make(map[*Session]bool)
+	for _, session := range m.GetSessions() {
+		open[session] = true
+	}
+	var (
+		imported []*Session
+		firstErr error
+	)
+	for _, path := range paths {
+		session, err := m.ImportClaudeLog(path)
+		if errors.Is(err, ErrEmptyClaudeLog) {
+			continue
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if !open[session] {
+			open[session] = true
+			imported = append(imported, session)
+		}
+	}
+	return imported, firstErr
+}
@@ -0,0 +1,145 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const claudeLog = "testdata/claude/-home-dev-shop/0b5e7c1a.jsonl"
+
+func TestReadClaudeLog(t *testing.T) {
+	state, err := ReadClaudeLog(claudeLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type entry struct {
+		role Role
+		text string
+	}
+	want := []entry{
+		{RoleUser, "Why is the total wrong?"},
+		// One reply split over two lines that share its message ID.
+		{RoleAssistant, "Let me look at the cart."},
+		{RoleTool, "func Total() { ... }"},
+		// Lines without an ID are never merged.
+		{RoleAssistant, "Partial note without an ID."},
+		{RoleAssistant, "Another line without an ID."},
+		// The prompt goes before the tool results it was sent with.
+		{RoleUser, "Use the tax table."},
+		{RoleTool, "permission denied"},
+		{RoleAssistant, "Tax is added twice."},
+	}
+	if len(state.Transcript) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(state.Transcript), len(want), state.Transcript)
+	}
+	for i, w := range want {
+		got := state.Transcript[i]
+		if got.Role != w.role || len(got.Blocks) == 0 || got.Blocks[0].Text != w.text {
+			t.Errorf("entry %d = %s %+v, want %s %q", i, got.Role, got.Blocks, w.role, w.text)
+		}
+		if got.SessionID != state.ID {
+			t.Errorf("entry %d belongs to %q, want %q", i, got.SessionID, state.ID)
+		}
+	}
+
+	reply := state.Transcript[1]
+	if len(reply.Blocks) != 2 || reply.Blocks[1].Type != BlockToolUse || reply.Blocks[1].ToolName != "Read" || reply.Blocks[1].Text != `{"file_path":"cart.go"}` {
+		t.Errorf("split reply blocks = %+v", reply.Blocks)
+	}
+	if !state.Transcript[6].Blocks[0].IsError {
+		t.Error("the failed tool result is not marked as an error")
+	}
+
+	// Usage counts each message ID once, with its last figures, and leaves
+	// out the lines without an ID and the sidechain.
+	wantUsage := Usage{InputTokens: 300, OutputTokens: 30, CacheReadTokens: 50}
+	if state.Usage != wantUsage {
+		t.Errorf("usage = %+v, want %+v", state.Usage, wantUsage)
+	}
+	if usage := reply.Usage; usage == nil || *usage != (Usage{InputTokens: 100, OutputTokens: 20}) {
+		t.Errorf("split reply usage = %+v", usage)
+	}
+	if state.Transcript[3].Usage != nil {
+		t.Errorf("a line without an ID got usage %+v", state.Transcript[3].Usage)
+	}
+	if want := DefaultPriceTable().Cost("claude-sonnet-4-20250514", wantUsage); state.Cost != want {
+		t.Errorf("cost = %g, want %g", state.Cost, want)
+	}
+
+	if state.Config.ResumeID != "0b5e7c1a" || state.Config.WorkDir != "/home/dev/shop" || state.Config.Model != "claude-sonnet-4-20250514" {
+		t.Errorf("config = %+v", state.Config)
+	}
+	if state.Name != "shop: Fix the checkout total" || state.LastPrompt != "Use the tax table." {
+		t.Errorf("name %q, last prompt %q", state.Name, state.LastPrompt)
+	}
+	if state.Status != StatusStopped || state.StopReason != "imported from Claude Code" {
+		t.Errorf("status %s (%s)", state.Status, state.StopReason)
+	}
+	if !state.CreatedAt.Equal(time.Date(2025, 3, 1, 10, 0, 1, 0, time.UTC)) || !state.UpdatedAt.Equal(time.Date(2025, 3, 1, 10, 0, 15, 0, time.UTC)) {
+		t.Errorf("created %s, updated %s", state.CreatedAt, state.UpdatedAt)
+	}
+}
+
+func TestImportClaudeLogs(t *testing.T) {
+	m := NewManager()
+	imported, err := m.ImportClaudeLogs(filepath.Join("testdata", "claude"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported) != 1 || imported[0].GetConfig().ResumeID != "0b5e7c1a" {
+		t.Fatalf("imported %d sessions, want the one conversation", len(imported))
+	}
+	if _, err := ReadClaudeLog("testdata/claude/-home-dev-shop/summary-only.jsonl"); !errors.Is(err, ErrEmptyClaudeLog) {
+		t.Errorf("reading a summary-only log = %v, want ErrEmptyClaudeLog", err)
+	}
+
+	// An open conversation is not imported twice.
+	again, err := m.ImportClaudeLogs(filepath.Join("testdata", "claude"))
+	if err != nil || len(again) != 0 {
+		t.Errorf("second import = %d sessions, %v", len(again), err)
+	}
+}
+
+func TestImportedSessionFollowsNewClaudeSessionID(t *testing.T) {
+	_, log := fakeCLI(t)
+	t.Setenv("FAKE_CLAUDE_RESUMED", "sess-forked")
+	path, err := filepath.Abs(filepath.Join("testdata", "fake-claude"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager()
+	m.SetBackendFactory(func(s *Session) Backend {
+		backend := NewCLIBackend(path)
+		backend.Resume(s.Config.ResumeID)
+		return backend
+	})
+
+	sess, err := m.ImportClaudeLog(claudeLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sess.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := sess.SendInput("Carry on."); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "--resume 0b5e7c1a") {
+		t.Errorf("the CLI was not asked to resume the imported conversation:\n%s", data)
+	}
+	// A restored copy of the session must resume where the CLI carried on.
+	if id := sess.GetConfig().ResumeID; id != "sess-forked" {
+		t.Errorf("ResumeID = %q, want the CLI's new session ID", id)
+	}
+}
@@ -0,0 +1,312 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+const maxStderrBytes = 4096
+
+// CLIBackend drives the Claude Code CLI in non-interactive stream-json mode.
+// Each Send spawns one `claude -p` process and writes the prompt to its stdin,
+// where a leading "-" cannot be mistaken for a flag. The CLI's own session ID
+// is kept so later turns continue the same conversation with --resume.
+type CLIBackend struct {
+	// Path is the claude executable, looked up in $PATH when not absolute.
+	Path string
+	// Args are extra arguments appended to every invocation.
+	Args []string
+	// Dir is the working directory of the spawned process.
+	Dir string
+
+	mu        sync.Mutex
+	sessionID string
+	cancel    context.CancelFunc
+	closed    bool
+}
+
+func NewCLIBackend(path string) *CLIBackend {
+	if path == "" {
+		path = "claude"
+	}
+	return &CLIBackend{Path: path}
+}
+
+// ClaudeSessionID returns the CLI's session ID once the first turn started.
+func (b *CLIBackend) ClaudeSessionID() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sessionID
+}
+
+// Resume makes the next turn continue the CLI session id rather than start
+// a new one.
+func (b *CLIBackend) Resume(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sessionID = id
+}
+
+func (b *CLIBackend) Send(ctx context.Context, req Request, emit func(Chunk)) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBackendClosed
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	b.cancel = cancel
+	args := []string{"-p", "--output-format", "stream-json", "--verbose"}
+	if b.sessionID != "" {
+		args = append(args, "--resume", b.sessionID)
+	}
+	if req.Model != "" {
+		args = append(args, "--model", req.Model)
+	}
+	if req.System != "" {
+		args = append(args, "--append-system-prompt", req.System)
+	}
+	args = append(args, b.Args...)
+	b.mu.Unlock()
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, b.Path, args...)
+	cmd.Dir = b.Dir
+	cmd.Stdin = strings.NewReader(req.Input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &limitedWriter{w: &stderr, n: maxStderrBytes}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting %s: %w", b.Path, err)
+	}
+
+	parseErr := b.readEvents(stdout, emit)
+	// Drain whatever is left so the process is never blocked on a full pipe.
+	_, _ = io.Copy(io.Discard, stdout)
+	waitErr := cmd.Wait()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if parseErr != nil {
+		return parseErr
+	}
+	if waitErr != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w: %s", b.Path, waitErr, msg)
+		}
+		return fmt.Errorf("%s: %w", b.Path, waitErr)
+	}
+	return nil
+}
+
+type cliEvent struct {
+	Type      string      `json:"type"`
+	Subtype   string      `json:"subtype"`
+	SessionID string      `json:"session_id"`
+	Message   *cliMessage `json:"message"`
+	Result    string      `json:"result"`
+	IsError   bool        `json:"is_error"`
+	Usage     *cliUsage   `json:"usage"`
+}
+
+type cliMessage struct {
+	Role    string       `json:"role"`
+	Content []cliContent `json:"content"`
+}
+
+type cliContent struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+type cliUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+}
+
+func (u cliUsage) toUsage() Usage {
+	return Usage{
+		InputTokens:      u.InputTokens,
+		OutputTokens:     u.OutputTokens,
+		CacheReadTokens:  u.CacheReadInputTokens,
+		CacheWriteTokens: u.CacheCreationInputTokens,
+	}
+}
+
+func (b *CLIBackend) readEvents(r io.Reader, emit func(Chunk)) error {
+	reader := bufio.NewReader(r)
+	var resultErr error
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var event cliEvent
+			if jsonErr := json.Unmarshal(line, &event); jsonErr != nil {
+				// Non-JSON lines are diagnostics, not part of the stream.
+				continue
+			}
+			if evErr := b.handleEvent(event, emit); evErr != nil {
+				resultErr = evErr
+			}
+		}
+		if err == io.EOF {
+			return resultErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (b *CLIBackend) handleEvent(event cliEvent, emit func(Chunk)) error {
+	if event.SessionID != "" {
+		b.mu.Lock()
+		changed := event.SessionID != b.sessionID
+		b.sessionID = event.SessionID
+		b.mu.Unlock()
+		if changed {
+			emit(Chunk{Type: ChunkSessionID, Text: event.SessionID})
+		}
+	}
+
+	switch event.Type {
+	case "assistant":
+		if event.Message == nil {
+			return nil
+		}
+		for _, block := range event.Message.Content {
+			switch block.Type {
+			case "text":
+				emit(Chunk{Type: ChunkText, Text: block.Text})
+			case "tool_use":
+				emit(Chunk{Type: ChunkToolUse, ToolID: block.ID, ToolName: block.Name, Text: string(block.Input)})
+			}
+		}
+
+	case "user":
+		if event.Message == nil {
+			return nil
+		}
+		for _, block := range event.Message.Content {
+			if block.Type == "tool_result" {
+				emit(Chunk{
+					Type:    ChunkToolResult,
+					ToolID:  block.ToolUseID,
+					Text:    toolResultText(block.Content),
+					IsError: block.IsError,
+				})
+			}
+		}
+
+	case "result":
+		if event.Usage != nil {
+			emit(Chunk{Type: ChunkUsage, Usage: event.Usage.toUsage()})
+		}
+		if event.IsError || (event.Subtype != "" && event.Subtype != "success") {
+			msg := event.Result
+			if msg == "" {
+				msg = event.Subtype
+			}
+			return errors.New(msg)
+		}
+	}
+	return nil
+}
+
+// toolResultText flattens tool_result content, which is either a string or a
+// list of content blocks.
+func toolResultText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var blocks []cliContent
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return string(raw)
+	}
+	parts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		if block.Type == "text" {
+			parts = append(parts, block.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Start checks that the claude executable can be found.
+func (b *CLIBackend) Start(ctx context.Context) error {
+	if _, err := exec.LookPath(b.Path); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.closed = false
+	b.mu.Unlock()
+	return nil
+}
+
+func (b *CLIBackend) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.cancel != nil {
+		b.cancel()
+	}
+}
+
+func (b *CLIBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	if b.cancel != nil {
+		b.cancel()
+	}
+	return nil
+}
+
+func (b *CLIBackend) Capabilities() Capabilities {
+	return Capabilities{Streaming: true, Cancel: true, Tools: true}
+}
+
+// limitedWriter keeps the first n bytes written and discards the rest.
+type limitedWriter struct {
+	w io.Writer
+	n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	size := len(p)
+	if l.n <= 0 {
+		return size, nil
+	}
+	if len(p) > l.n {
+		p = p[:l.n]
+	}
+	written, err := l.w.Write(p)
+	l.n -= written
+	if err != nil {
+		return written, err
+	}
+	return size, nil
+}
@@ -0,0 +1,106 @@
+package session
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeCLI returns a backend running testdata/fake-claude and the file the
+// script logs its arguments and prompts to.
+func fakeCLI(t *testing.T) (*CLIBackend, string) {
+	t.Helper()
+	path, err := filepath.Abs(filepath.Join("testdata", "fake-claude"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := filepath.Join(t.TempDir(), "calls.log")
+	t.Setenv("FAKE_CLAUDE_LOG", log)
+	return NewCLIBackend(path), log
+}
+
+func sendCLI(t *testing.T, b *CLIBackend, input string) ([]Chunk, error) {
+	t.Helper()
+	var chunks []Chunk
+	err := b.Send(context.Background(), Request{Input: input}, func(chunk Chunk) {
+		chunks = append(chunks, chunk)
+	})
+	return chunks, err
+}
+
+func TestCLIBackendStream(t *testing.T) {
+	b, _ := fakeCLI(t)
+	chunks, err := sendCLI(t, b, "List the files")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Chunk{
+		{Type: ChunkSessionID, Text: "sess-new"},
+		{Type: ChunkText, Text: "Listing files."},
+		{Type: ChunkToolUse, ToolID: "toolu_1", ToolName: "Bash", Text: `{"command":"ls"}`},
+		{Type: ChunkToolResult, ToolID: "toolu_1", Text: "go.mod"},
+		{Type: ChunkText, Text: "Done."},
+		{Type: ChunkUsage, Usage: Usage{InputTokens: 10, OutputTokens: 5, CacheReadTokens: 3, CacheWriteTokens: 1}},
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("got %d chunks, want %d: %+v", len(chunks), len(want), chunks)
+	}
+	for i := range want {
+		if chunks[i] != want[i] {
+			t.Errorf("chunk %d = %+v, want %+v", i, chunks[i], want[i])
+		}
+	}
+	if id := b.ClaudeSessionID(); id != "sess-new" {
+		t.Errorf("session ID = %q, want sess-new", id)
+	}
+}
+
+func TestCLIBackendResumesSessionAndPassesPromptOnStdin(t *testing.T) {
+	b, log := fakeCLI(t)
+	if _, err := sendCLI(t, b, "first"); err != nil {
+		t.Fatal(err)
+	}
+	// A prompt that looks like a flag must reach the CLI as the prompt.
+	if _, err := sendCLI(t, b, "- a list item\n- another"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := string(data)
+	if !strings.Contains(calls, "prompt:- a list item\n- another\n") {
+		t.Errorf("the prompt did not reach stdin intact:\n%s", calls)
+	}
+	first, second, _ := strings.Cut(calls, "prompt:first\n")
+	if strings.Contains(first, "--resume") {
+		t.Errorf("the first turn resumed a session: %s", first)
+	}
+	if !strings.Contains(second, "--resume sess-new") {
+		t.Errorf("the second turn did not resume sess-new: %s", second)
+	}
+}
+
+func TestCLIBackendErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "error result", input: "fail", wantErr: "Prompt is too long"},
+		{name: "non-zero exit", input: "crash", wantErr: "exit status 2: fake-claude: not logged in"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := fakeCLI(t)
+			_, err := sendCLI(t, b, tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SessionConfig holds the per-session settings sent with every request. Zero
+// values fall back to the backend's defaults.
+type SessionConfig struct {
+	Model        string   `json:"model,omitempty"`
+	SystemPrompt string   `json:"system_prompt,omitempty"`
+	MaxTokens    int      `json:"max_tokens,omitempty"`
+	Temperature  *float64 `json:"temperature,omitempty"`
+	Budget       Budget   `json:"budget,omitzero"`
+
+	// ResumeID and WorkDir tie the session to an existing Claude Code
+	// conversation, which the cli backend continues with --resume.
+	ResumeID string `json:"resume_id,omitempty"`
+	WorkDir  string `json:"work_dir,omitempty"`
+
+	// Scratchpads are the names of shared scratchpads prepended to every
+	// prompt.
+	Scratchpads []string `json:"scratchpads,omitempty"`
+}
+
+func DefaultSessionConfig() SessionConfig {
+	return SessionConfig{
+		Model:     DefaultModel,
+		MaxTokens: DefaultMaxTokens,
+	}
+}
+
+// Summary renders the config on a single line for display.
+func (c SessionConfig) Summary() string {
+	model := c.Model
+	if model == "" {
+		model = "default model"
+	}
+	parts := []string{model}
+	if c.MaxTokens > 0 {
+		parts = append(parts, fmt.Sprintf("max %d tokens", c.MaxTokens))
+	}
+	if c.Temperature != nil {
+		parts = append(parts, fmt.Sprintf("temp %.2g", *c.Temperature))
+	}
+	if c.SystemPrompt != "" {
+		prompt := strings.ReplaceAll(c.SystemPrompt, "\n", " ")
+		if runes := []rune(prompt); len(runes) > 40 {
+			prompt = string(runes[:37]) + "..."
+		}
+		parts = append(parts, fmt.Sprintf("system: %q", prompt))
+	}
+	if c.ResumeID != "" {
+		id := c.ResumeID
+		if len(id) > 8 {
+			id = id[:8]
+		}
+		parts = append(parts, "resumes "+id)
+	}
+	if len(c.Scratchpads) > 0 {
+		parts = append(parts, "pads: "+strings.Join(c.Scratchpads, ", "))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// Message is one turn of the conversation history sent to a backend.
+type Message struct {
+	Role    Role
+	Content string
+}
+
+// buildMessages turns a transcript into alternating user/assistant messages.
+// System notes and tool traffic are left out, and consecutive entries with
+// the same role, such as a prompt whose reply failed, are merged.
+func buildMessages(entries []Entry) []Message {
+	var messages []Message
+	for _, entry := range entries {
+		if entry.Role != RoleUser && entry.Role != RoleAssistant {
+			continue
+		}
+		text := entry.Text()
+		if text == "" {
+			continue
+		}
+		if n := len(messages); n > 0 && messages[n-1].Role == entry.Role {
+			messages[n-1].Content += "\n\n" + text
+			continue
+		}
+		messages = append(messages, Message{Role: entry.Role, Content: text})
+	}
+	return messages
+}
@@ -0,0 +1,19 @@
+package session
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSummaryTruncatesSystemPromptByRunes(t *testing.T) {
+	config := SessionConfig{Model: "opus", SystemPrompt: strings.Repeat("日本語", 20)}
+	got := config.Summary()
+	if !utf8.ValidString(got) {
+		t.Fatalf("Summary() = %q, not valid UTF-8", got)
+	}
+	want := `opus · system: "` + string([]rune(config.SystemPrompt)[:37]) + `..."`
+	if got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
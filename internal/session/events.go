@@ -0,0 +1,85 @@
+package session
+
+type EventType int
+
+const (
+	EventOutputAppended EventType = iota
+	EventStatusChanged
+	EventSessionCreated
+	EventSessionRemoved
+	EventUsageUpdated
+	EventSessionUpdated
+	EventTurnCompleted
+	EventScratchpadUpdated
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventOutputAppended:
+		return "output"
+	case EventStatusChanged:
+		return "status"
+	case EventSessionCreated:
+		return "created"
+	case EventSessionRemoved:
+		return "removed"
+	case EventUsageUpdated:
+		return "usage"
+	case EventSessionUpdated:
+		return "updated"
+	case EventTurnCompleted:
+		return "turn"
+	case EventScratchpadUpdated:
+		return "scratchpad"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes a change to a session managed by a Manager. Text is set for
+// EventOutputAppended and Status for EventStatusChanged; for
+// EventScratchpadUpdated Text is the scratchpad's name and SessionID its
+// writer, if a session wrote it.
+type Event struct {
+	Type      EventType
+	SessionID string
+	Status    Status
+	Text      string
+}
+
+const subscriberBuffer = 256
+
+// Subscribe returns a channel of session events and a function that ends the
+// subscription and closes the channel. Slow subscribers miss events rather
+// than block the sessions producing them.
+func (m *Manager) Subscribe() (<-chan Event, func()) {
+	m.subMu.Lock()
+	defer m.subMu.Unlock()
+
+	id := m.nextSubID
+	m.nextSubID++
+	ch := make(chan Event, subscriberBuffer)
+	m.subscribers[id] = ch
+
+	return ch, func() {
+		m.subMu.Lock()
+		defer m.subMu.Unlock()
+
+		if ch, ok := m.subscribers[id]; ok {
+			delete(m.subscribers, id)
+			close(ch)
+		}
+	}
+}
+
+func (m *Manager) publish(event Event) {
+	m.subMu.Lock()
+	defer m.subMu.Unlock()
+
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
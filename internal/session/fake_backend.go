@@ -0,0 +1,81 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeBackend produces deterministic replies without any network access. It is
+// used for demo mode and tests.
+type FakeBackend struct {
+	// Delay is the pause between streamed words. Zero replies instantly.
+	Delay time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	closed bool
+}
+
+func NewFakeBackend() *FakeBackend {
+	return &FakeBackend{}
+}
+
+func (b *FakeBackend) Send(ctx context.Context, req Request, emit func(Chunk)) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBackendClosed
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	b.cancel = cancel
+	b.mu.Unlock()
+	defer cancel()
+
+	response := fmt.Sprintf("Claude response to: %s", strings.ReplaceAll(strings.TrimSpace(req.Input), "\n", " "))
+	// Word counts stand in for tokens so usage tracking has something to show.
+	usage := Usage{InputTokens: len(strings.Fields(req.Input)), OutputTokens: len(strings.Fields(response))}
+	if b.Delay == 0 {
+		emit(Chunk{Type: ChunkText, Text: response})
+		emit(Chunk{Type: ChunkUsage, Usage: usage})
+		return nil
+	}
+
+	words := strings.SplitAfter(response, " ")
+	for _, word := range words {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(b.Delay):
+		}
+		emit(Chunk{Type: ChunkText, Text: word})
+	}
+	emit(Chunk{Type: ChunkUsage, Usage: usage})
+	return nil
+}
+
+func (b *FakeBackend) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.cancel != nil {
+		b.cancel()
+	}
+}
+
+func (b *FakeBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	if b.cancel != nil {
+		b.cancel()
+	}
+	return nil
+}
+
+func (b *FakeBackend) Capabilities() Capabilities {
+	return Capabilities{Streaming: b.Delay > 0, Cancel: true}
+}
@@ -0,0 +1,51 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// crockford is Crockford's base32 alphabet in lower case: no i, l, o or u, so
+// IDs stay readable and unambiguous when typed.
+const crockford = "0123456789abcdefghjkmnpqrstvwxyz"
+
+var idState struct {
+	mu     sync.Mutex
+	lastMS int64
+	lastR  uint32
+}
+
+// generateID returns a 16 character ID: 10 characters of millisecond
+// timestamp followed by 6 random ones. IDs sort by creation time, and IDs
+// made within the same millisecond increment the random part so they stay
+// unique and ordered.
+func generateID() string {
+	idState.mu.Lock()
+	ms := time.Now().UnixMilli()
+	var r uint32
+	if ms <= idState.lastMS {
+		ms = idState.lastMS
+		r = idState.lastR + 1
+	} else {
+		var buf [4]byte
+		_, _ = rand.Read(buf[:])
+		// Leave headroom below 2^30 so increments within one millisecond
+		// never overflow into the timestamp.
+		r = binary.BigEndian.Uint32(buf[:]) & (1<<29 - 1)
+	}
+	idState.lastMS, idState.lastR = ms, r
+	idState.mu.Unlock()
+
+	var id [16]byte
+	for i := 9; i >= 0; i-- {
+		id[i] = crockford[ms&31]
+		ms >>= 5
+	}
+	for i := 15; i >= 10; i-- {
+		id[i] = crockford[r&31]
+		r >>= 5
+	}
+	return string(id[:])
+}
@@ -0,0 +1,24 @@
+package session
+
+import "testing"
+
+func TestGenerateIDUniqueAndOrdered(t *testing.T) {
+	// Far more IDs than fit in one millisecond each.
+	ids := make([]string, 10000)
+	for i := range ids {
+		ids[i] = generateID()
+	}
+	seen := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		if len(id) != 16 {
+			t.Fatalf("ID %q is %d characters long", id, len(id))
+		}
+		if seen[id] {
+			t.Fatalf("ID %q was made twice", id)
+		}
+		seen[id] = true
+		if i > 0 && id <= ids[i-1] {
+			t.Fatalf("ID %d %q sorts before ID %d %q", i, id, i-1, ids[i-1])
+		}
+	}
+}
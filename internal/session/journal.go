@@ -0,0 +1,399 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const journalExt = ".jsonl"
+
+// journalRecord is one line of a session journal. Replaying the records of a
+// journal in order rebuilds the session.
+type journalRecord struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+
+	// meta: the session's identity and settings.
+	Meta *SessionState `json:"meta,omitempty"`
+	// entry, delta, usage and truncate address the transcript by index.
+	Index *int          `json:"index,omitempty"`
+	Entry *Entry        `json:"entry,omitempty"`
+	Block *ContentBlock `json:"block,omitempty"`
+	// usage: the entry's usage followed by the session totals.
+	EntryUsage *Usage   `json:"entry_usage,omitempty"`
+	Usage      *Usage   `json:"usage,omitempty"`
+	Cost       *float64 `json:"cost,omitempty"`
+	// status: the new status and the stop reason at the time.
+	Status *Status `json:"status,omitempty"`
+	Reason string  `json:"reason,omitempty"`
+}
+
+const (
+	recordMeta     = "meta"
+	recordEntry    = "entry"
+	recordDelta    = "delta"
+	recordUsage    = "usage"
+	recordTruncate = "truncate"
+	recordStatus   = "status"
+	recordClosed   = "closed"
+)
+
+// Journal is an append-only JSONL log of everything that happens to one
+// session. A journal without a closing record belongs to a run that crashed
+// or was killed and can be recovered.
+type Journal struct {
+	path string
+	file *os.File
+	mu   sync.Mutex
+	err  error
+}
+
+// openJournal opens path for appending, or empties it first when truncate is
+// set. A partially written trailing line, left by a crash mid-write, is cut
+// off so new records start on a fresh line.
+func openJournal(path string, truncate bool) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	flags := os.O_WRONLY | os.O_APPEND | os.O_CREATE
+	if truncate {
+		flags |= os.O_TRUNC
+	} else if err := repairJournal(path); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{path: path, file: file}, nil
+}
+
+func repairJournal(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 || data[len(data)-1] == '\n' {
+		return nil
+	}
+	return os.Truncate(path, int64(bytes.LastIndexByte(data, '\n')+1))
+}
+
+func (j *Journal) Path() string {
+	return j.path
+}
+
+func (j *Journal) write(record journalRecord) {
+	if j == nil {
+		return
+	}
+	record.Time = time.Now()
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil || j.err != nil {
+		return
+	}
+	// One write per record keeps lines whole unless the process dies
+	// mid-syscall, which repairJournal takes care of.
+	_, j.err = j.file.Write(append(data, '\n'))
+}
+
+// close ends the journal. A clean close is recorded so the session is not
+// offered for recovery on the next start.
+func (j *Journal) close(clean bool) error {
+	if j == nil {
+		return nil
+	}
+	if clean {
+		j.write(journalRecord{Type: recordClosed})
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return j.err
+	}
+	err := j.file.Close()
+	j.file = nil
+	if j.err != nil {
+		return j.err
+	}
+	return err
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+// journalMeta records the session's identity and settings. Callers must hold
+// s.mu.
+func (s *Session) journalMeta() {
+	s.journal.write(journalRecord{Type: recordMeta, Meta: &SessionState{
+		ID:        s.ID,
+		Name:      s.Name,
+		Alias:     s.Alias,
+		Config:    s.Config,
+		CreatedAt: s.CreatedAt,
+	}})
+}
+
+// attachJournal starts journaling the session into a new journal, first
+// writing out its current state so the journal alone is enough to rebuild it.
+func (s *Session) attachJournal(journal *Journal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.journal = journal
+	s.journalMeta()
+	for i := range s.Transcript {
+		s.journal.write(journalRecord{Type: recordEntry, Index: intPtr(i), Entry: &s.Transcript[i]})
+	}
+	usage, cost := s.Usage, s.Cost
+	s.journal.write(journalRecord{Type: recordUsage, Usage: &usage, Cost: &cost})
+	status := s.Status
+	s.journal.write(journalRecord{Type: recordStatus, Status: &status, Reason: s.StopReason})
+}
+
+// ReadJournal replays a journal into a SessionState. It reports whether the
+// journal was closed cleanly. Lines that cannot be decoded, such as a final
+// line cut short by a crash, are skipped.
+func ReadJournal(path string) (SessionState, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return SessionState{}, false, err
+	}
+	defer file.Close()
+
+	var (
+		state  SessionState
+		closed bool
+		reader = bufio.NewReader(file)
+	)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			var record journalRecord
+			if json.Unmarshal(line, &record) == nil {
+				closed = applyRecord(&state, record)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return state, closed, err
+		}
+	}
+	if state.ID == "" {
+		return state, closed, fmt.Errorf("journal %s has no session metadata", path)
+	}
+	return state, closed, nil
+}
+
+// applyRecord applies one record and reports whether it closed the journal.
+func applyRecord(state *SessionState, record journalRecord) bool {
+	switch record.Type {
+	case recordMeta:
+		if record.Meta != nil {
+			state.ID = record.Meta.ID
+			state.Name = record.Meta.Name
+			state.Alias = record.Meta.Alias
+			state.Config = record.Meta.Config
+			state.CreatedAt = record.Meta.CreatedAt
+		}
+
+	case recordEntry:
+		if record.Index != nil && record.Entry != nil && *record.Index <= len(state.Transcript) {
+			if *record.Index == len(state.Transcript) {
+				state.Transcript = append(state.Transcript, *record.Entry)
+			} else {
+				state.Transcript[*record.Index] = *record.Entry
+			}
+			if record.Entry.Role == RoleUser {
+				state.LastPrompt = record.Entry.Text()
+			}
+		}
+
+	case recordDelta:
+		if record.Index == nil || record.Block == nil || *record.Index > len(state.Transcript) {
+			break
+		}
+		if *record.Index == len(state.Transcript) {
+			state.Transcript = append(state.Transcript, Entry{Role: RoleAssistant, Timestamp: record.Time, SessionID: state.ID})
+		}
+		entry := &state.Transcript[*record.Index]
+		if last := len(entry.Blocks) - 1; record.Block.Type == BlockText && last >= 0 && entry.Blocks[last].Type == BlockText {
+			entry.Blocks[last].Text += record.Block.Text
+		} else {
+			entry.Blocks = append(entry.Blocks, *record.Block)
+		}
+
+	case recordUsage:
+		if record.Index != nil && record.EntryUsage != nil && *record.Index < len(state.Transcript) {
+			usage := *record.EntryUsage
+			state.Transcript[*record.Index].Usage = &usage
+		}
+		if record.Usage != nil {
+			state.Usage = *record.Usage
+		}
+		if record.Cost != nil {
+			state.Cost = *record.Cost
+		}
+
+	case recordTruncate:
+		if record.Index != nil && *record.Index < len(state.Transcript) {
+			state.Transcript = state.Transcript[:*record.Index]
+		}
+
+	case recordStatus:
+		if record.Status != nil {
+			state.Status = *record.Status
+			state.StopReason = record.Reason
+		}
+
+	case recordClosed:
+		return true
+	}
+
+	if !record.Time.IsZero() {
+		state.UpdatedAt = record.Time
+	}
+	return false
+}
+
+// RecoverableJournals lists the journals in dir that were never closed,
+// oldest session first.
+func RecoverableJournals(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), journalExt) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		state, closed, err := ReadJournal(path)
+		if err != nil || closed || state.Status == StatusKilled {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	// IDs sort by creation time, and so do the file names.
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// SetJournalDir turns on journaling: every session, existing or new, gets a
+// journal in dir named after its ID.
+func (m *Manager) SetJournalDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.journalDir = dir
+	m.mu.Unlock()
+
+	for _, session := range m.GetSessions() {
+		if err := m.journalSession(session); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Manager) JournalDir() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.journalDir
+}
+
+func (m *Manager) journalSession(session *Session) error {
+	dir := m.JournalDir()
+	if dir == "" {
+		return nil
+	}
+	session.mu.RLock()
+	hasJournal := session.journal != nil
+	session.mu.RUnlock()
+	if hasJournal {
+		return nil
+	}
+
+	journal, err := openJournal(filepath.Join(dir, session.ID+journalExt), true)
+	if err != nil {
+		return err
+	}
+	session.attachJournal(journal)
+	return nil
+}
+
+// RecoverJournals rebuilds, in the stopped state, the sessions whose journals
+// in the journal directory were left open by a crashed or killed run. Their
+// journals are reused, so their history continues where it left off.
+func (m *Manager) RecoverJournals() ([]*Session, error) {
+	dir := m.JournalDir()
+	if dir == "" {
+		return nil, errors.New("journaling is not enabled")
+	}
+	paths, err := RecoverableJournals(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var recovered []*Session
+	for _, path := range paths {
+		state, _, err := ReadJournal(path)
+		if err != nil || m.GetSession(state.ID) != nil {
+			continue
+		}
+		journal, err := openJournal(path, false)
+		if err != nil {
+			return recovered, err
+		}
+		recovered = append(recovered, m.restoreState(state, "recovered after an interrupted run", journal))
+	}
+	return recovered, nil
+}
+
+// DiscardJournals closes the journals left by an interrupted run without
+// recovering them, so they are not offered again.
+func DiscardJournals(dir string) error {
+	paths, err := RecoverableJournals(dir)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		journal, err := openJournal(path, false)
+		if err != nil {
+			return err
+		}
+		if err := journal.close(true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
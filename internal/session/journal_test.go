@@ -0,0 +1,114 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func sameEntries(t *testing.T, got, want []Entry) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Role != want[i].Role || got[i].Text() != want[i].Text() || (got[i].Usage == nil) != (want[i].Usage == nil) {
+			t.Errorf("entry %d = %s %q, want %s %q", i, got[i].Role, got[i].Text(), want[i].Role, want[i].Text())
+		}
+	}
+}
+
+func TestJournalRecoversFromPartialLine(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager()
+	if err := m.SetJournalDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	sess := m.CreateSession("crashy", m.DefaultConfig())
+	for _, prompt := range []string{"first", "second"} {
+		if err := sess.SendInput(prompt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := sess.GetTranscript()
+
+	// Crash while writing the next record: half a line, no newline.
+	path := filepath.Join(dir, sess.ID+journalExt)
+	next := TextEntry(RoleUser, "third")
+	record, err := json.Marshal(journalRecord{Type: recordEntry, Index: intPtr(len(want)), Entry: &next})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write(record[:len(record)/2])
+	file.Close()
+
+	state, closed, err := ReadJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closed {
+		t.Error("the journal reads as closed")
+	}
+	if state.ID != sess.ID || state.Name != "crashy" {
+		t.Errorf("replayed session %s %q, want %s crashy", state.ID, state.Name, sess.ID)
+	}
+	sameEntries(t, state.Transcript, want)
+
+	// Recovery cuts the partial line off so new records start on their own.
+	recovering := NewManager()
+	if err := recovering.SetJournalDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := recovering.RecoverJournals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recovered) != 1 {
+		t.Fatalf("recovered %d sessions, want 1", len(recovered))
+	}
+
+	restored := recovered[0]
+	if restored.GetStatus() != StatusStopped {
+		t.Errorf("recovered session is %s, want stopped", restored.GetStatus())
+	}
+	sameEntries(t, restored.GetTranscript(), want)
+	if err := restored.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.SendInput("third"); err != nil {
+		t.Fatal(err)
+	}
+	if err := recovering.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, line := range bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) {
+		if !json.Valid(line) {
+			t.Errorf("journal line %d is not a whole record: %s", i+1, line)
+		}
+	}
+
+	state, closed, err = ReadJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !closed {
+		t.Error("the journal was not closed")
+	}
+	sameEntries(t, state.Transcript, restored.GetTranscript())
+	sameEntries(t, state.Transcript[:len(want)], want)
+	if last := state.Transcript[len(state.Transcript)-1]; last.Text() != "Claude response to: third" {
+		t.Errorf("last entry = %q, want the reply to the new turn", last.Text())
+	}
+}
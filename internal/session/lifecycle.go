@@ -0,0 +1,171 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// Starter is implemented by backends that need to be brought up before the
+// first request, e.g. to check that an executable exists.
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// transitions lists the statuses each status may move to. Moving to the
+// current status is always allowed and changes nothing.
+var transitions = map[Status][]Status{
+	StatusIdle:       {StatusConnecting, StatusStopped, StatusKilled},
+	StatusConnecting: {StatusRunning, StatusIdle, StatusError, StatusStopped, StatusKilled},
+	StatusRunning:    {StatusConnecting, StatusIdle, StatusError, StatusStopped, StatusKilled},
+	StatusError:      {StatusConnecting, StatusIdle, StatusStopped, StatusKilled},
+	StatusStopped:    {StatusIdle, StatusKilled},
+	StatusKilled:     {},
+}
+
+func canTransition(from, to Status) bool {
+	if from == to {
+		return true
+	}
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Start brings a new or stopped session to StatusIdle, starting its backend
+// if the backend needs it.
+func (s *Session) Start(ctx context.Context) error {
+	s.mu.RLock()
+	status := s.Status
+	backend := s.backend
+	s.mu.RUnlock()
+
+	if status != StatusStopped && status != StatusIdle {
+		return fmt.Errorf("%w: cannot start a session that is %s", ErrInvalidTransition, status)
+	}
+	if backend == nil {
+		return ErrBackendClosed
+	}
+	if starter, ok := backend.(Starter); ok {
+		if err := starter.Start(ctx); err != nil {
+			return fmt.Errorf("starting backend: %w", err)
+		}
+	}
+
+	if err := s.SetStatus(StatusIdle); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.StopReason = ""
+	s.mu.Unlock()
+	if status == StatusStopped {
+		s.AddOutput("Session started")
+	}
+	return nil
+}
+
+// Stop pauses the session: any in-flight request is cancelled and no new ones
+// are accepted until Start, but the transcript and backend are kept.
+func (s *Session) Stop(reason string) error {
+	s.mu.RLock()
+	status := s.Status
+	s.mu.RUnlock()
+
+	if !canTransition(status, StatusStopped) {
+		return fmt.Errorf("%w: cannot stop a session that is %s", ErrInvalidTransition, status)
+	}
+	if status == StatusStopped {
+		return nil
+	}
+	if reason == "" {
+		reason = "stopped by user"
+	}
+	s.halt(reason)
+	return nil
+}
+
+// Kill ends the session for good, cancelling its request and closing the
+// backend. A killed session cannot be started again.
+func (s *Session) Kill() error {
+	s.mu.Lock()
+	if s.Status == StatusKilled {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: session already killed", ErrInvalidTransition)
+	}
+	s.StopReason = "killed by user"
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	// Mark the session killed before cancelling, so a request in flight
+	// reports why it ended rather than being treated as cancelled.
+	if err := s.SetStatus(StatusKilled); err != nil {
+		return err
+	}
+	if cancel != nil {
+		cancel()
+	}
+	return s.Close()
+}
+
+// SaveTranscript writes the session's transcript to path as JSON.
+func (s *Session) SaveTranscript(path string) error {
+	s.mu.RLock()
+	data, err := json.MarshalIndent(struct {
+		ID         string        `json:"id"`
+		Name       string        `json:"name"`
+		Config     SessionConfig `json:"config"`
+		Transcript []Entry       `json:"transcript"`
+	}{s.ID, s.Name, s.Config, s.Transcript}, "", "  ")
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// KillSession kills the session and removes it from the manager. When
+// transcriptPath is set the transcript is saved there first.
+func (m *Manager) KillSession(id, transcriptPath string) error {
+	session := m.GetSession(id)
+	if session == nil {
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	if transcriptPath != "" {
+		if err := session.SaveTranscript(transcriptPath); err != nil {
+			return fmt.Errorf("saving transcript: %w", err)
+		}
+	}
+	if err := session.Kill(); err != nil {
+		return err
+	}
+	m.RemoveSession(id)
+	return nil
+}
+
+// StartAll starts every stopped session and returns the first error.
+func (m *Manager) StartAll(ctx context.Context) error {
+	var firstErr error
+	for _, session := range m.GetSessions() {
+		if session.GetStatus() != StatusStopped {
+			continue
+		}
+		if err := session.Start(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// StopAll stops every session that is not already stopped.
+func (m *Manager) StopAll(reason string) {
+	for _, session := range m.GetSessions() {
+		_ = session.Stop(reason)
+	}
+}
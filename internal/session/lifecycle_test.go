@@ -0,0 +1,35 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKillDuringSend(t *testing.T) {
+	m := NewManager()
+	m.SetBackendFactory(func(s *Session) Backend {
+		b := NewFakeBackend()
+		b.Delay = 20 * time.Millisecond
+		return b
+	})
+	sess := m.CreateSession("doomed", m.DefaultConfig())
+
+	done := make(chan error, 1)
+	go func() { done <- sess.SendInput("one two three four five six seven eight") }()
+	waitFor(t, "the session to be busy", sess.Busy)
+	if err := sess.Kill(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := <-done
+	if !errors.Is(err, ErrStopped) || err.Error() != "session stopped: killed by user" {
+		t.Errorf("SendInput() = %v, want the session stopped because it was killed", err)
+	}
+	if status := sess.GetStatus(); status != StatusKilled {
+		t.Errorf("status = %s, want killed", status)
+	}
+	if err := sess.Kill(); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("second Kill() = %v, want ErrInvalidTransition", err)
+	}
+}
@@ -0,0 +1,311 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxForwards caps a link that was created without a cap of its own.
+const DefaultMaxForwards = 10
+
+// busyRetryInterval is how often a forward checks whether a busy target
+// has become free.
+const busyRetryInterval = 200 * time.Millisecond
+
+var ErrLinkCycle = errors.New("link would create a cycle")
+
+// Link is a standing pipe: every reply the From session finishes is sent to
+// the To session as its next prompt, at most MaxForwards times.
+type Link struct {
+	From        string
+	To          string
+	Template    string
+	MaxForwards int
+	Forwarded   int
+
+	// pending counts forwards queued but not yet sent, so that they are held
+	// to the cap too.
+	pending int
+}
+
+// queuedForward is a rendered reply waiting to go down a link.
+type queuedForward struct {
+	link  *Link
+	pipe  Pipe
+	input string
+}
+
+// Exhausted reports whether the link has used up its forwards.
+func (l Link) Exhausted() bool {
+	return l.Forwarded >= l.MaxForwards
+}
+
+// AddLink connects from to to. Links that would let a reply come back around
+// to the session that sent it are refused with ErrLinkCycle.
+func (m *Manager) AddLink(from, to *Session, maxForwards int, template string) error {
+	if maxForwards <= 0 {
+		maxForwards = DefaultMaxForwards
+	}
+
+	m.mu.Lock()
+	if from == to {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: a session cannot link to itself", ErrLinkCycle)
+	}
+	for _, link := range m.links {
+		if link.From == from.ID && link.To == to.ID {
+			m.mu.Unlock()
+			return fmt.Errorf("%s is already linked to %s", from.DisplayName(), to.DisplayName())
+		}
+	}
+	if path := m.linkPath(to.ID, from.ID); path != nil {
+		names := []string{from.DisplayName()}
+		for _, id := range path {
+			names = append(names, m.byID[id].DisplayName())
+		}
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrLinkCycle, strings.Join(names, " → "))
+	}
+	m.links = append(m.links, &Link{From: from.ID, To: to.ID, Template: template, MaxForwards: maxForwards})
+	m.mu.Unlock()
+
+	m.publish(Event{Type: EventSessionUpdated, SessionID: from.ID})
+	return nil
+}
+
+// linkPath returns the sessions on a chain of links from one session to
+// another, both included, or nil if there is none. Callers must hold m.mu.
+func (m *Manager) linkPath(from, to string) []string {
+	visited := map[string]bool{from: true}
+	var walk func(id string) []string
+	walk = func(id string) []string {
+		if id == to {
+			return []string{id}
+		}
+		for _, link := range m.links {
+			if link.From != id || visited[link.To] {
+				continue
+			}
+			visited[link.To] = true
+			if rest := walk(link.To); rest != nil {
+				return append([]string{id}, rest...)
+			}
+		}
+		return nil
+	}
+	return walk(from)
+}
+
+// ParseLink reads "<source> | <target> [max N]" into a pair of sessions and
+// a forward cap, zero when none is given.
+func (m *Manager) ParseLink(expr string) (*Session, *Session, int, error) {
+	left, right, ok := strings.Cut(expr, "|")
+	target := strings.Fields(right)
+	if !ok || strings.TrimSpace(left) == "" || len(target) == 0 {
+		return nil, nil, 0, errors.New("a link looks like: <source> | <target> [max N]")
+	}
+
+	maxForwards := 0
+	switch {
+	case len(target) == 3 && target[1] == "max":
+		n, err := strconv.Atoi(target[2])
+		if err != nil || n < 1 {
+			return nil, nil, 0, fmt.Errorf("invalid forward cap %q", target[2])
+		}
+		maxForwards = n
+	case len(target) != 1:
+		return nil, nil, 0, errors.New("a link looks like: <source> | <target> [max N]")
+	}
+
+	from, err := m.Resolve(strings.TrimSpace(left))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	to, err := m.Resolve(target[0])
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return from, to, maxForwards, nil
+}
+
+// RemoveLink disconnects from and to, reporting whether they were linked.
+func (m *Manager) RemoveLink(from, to string) bool {
+	removed := m.removeLinks(func(link *Link) bool {
+		return link.From == from && link.To == to
+	})
+	return removed > 0
+}
+
+// RemoveLinksFrom drops every link out of a session and returns how many
+// there were.
+func (m *Manager) RemoveLinksFrom(id string) int {
+	return m.removeLinks(func(link *Link) bool { return link.From == id })
+}
+
+func (m *Manager) removeLinks(match func(*Link) bool) int {
+	m.mu.Lock()
+	kept := m.links[:0]
+	var changed []string
+	for _, link := range m.links {
+		if match(link) {
+			changed = append(changed, link.From)
+			continue
+		}
+		kept = append(kept, link)
+	}
+	m.links = kept
+	m.mu.Unlock()
+
+	for _, id := range changed {
+		m.publish(Event{Type: EventSessionUpdated, SessionID: id})
+	}
+	return len(changed)
+}
+
+// Links returns every link, in the order they were made.
+func (m *Manager) Links() []Link {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	links := make([]Link, len(m.links))
+	for i, link := range m.links {
+		links[i] = *link
+	}
+	return links
+}
+
+// LinksFrom returns the links out of a session.
+func (m *Manager) LinksFrom(id string) []Link {
+	var links []Link
+	for _, link := range m.Links() {
+		if link.From == id {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// forward queues a session's latest reply down each of its links that has
+// forwards left. The prompts are rendered now, while that reply is still the
+// latest, since a busy target may take them long after the source has moved
+// on.
+func (m *Manager) forward(id string) {
+	m.mu.Lock()
+	from := m.byID[id]
+	var due []*Link
+	for _, link := range m.links {
+		if link.From != id || link.Forwarded+link.pending >= link.MaxForwards {
+			continue
+		}
+		link.pending++
+		due = append(due, link)
+	}
+	m.mu.Unlock()
+
+	for _, link := range due {
+		to := m.GetSession(link.To)
+		if from == nil || to == nil {
+			m.settle(link, false)
+			continue
+		}
+		pipe := Pipe{From: from, To: to, Selection: Selection{Kind: SelectLastReply}, Template: link.Template}
+		input, err := pipe.Prompt()
+		if err != nil {
+			m.settle(link, false)
+			from.AddOutput(fmt.Sprintf("⇒ Forward to %s failed: %v", to.DisplayName(), err))
+			continue
+		}
+		m.enqueue(queuedForward{link: link, pipe: pipe, input: input})
+	}
+}
+
+// enqueue adds a forward to its target's queue, starting the goroutine that
+// works through the queue if it is not already running. Each target takes
+// its forwards one at a time in the order the replies finished.
+func (m *Manager) enqueue(f queuedForward) {
+	m.mu.Lock()
+	queue := m.forwards[f.link.To]
+	m.forwards[f.link.To] = append(queue, f)
+	m.mu.Unlock()
+
+	if len(queue) == 0 {
+		go m.deliverQueue(f.link.To)
+	}
+}
+
+// deliverQueue delivers the forwards queued for a target until none are left.
+func (m *Manager) deliverQueue(to string) {
+	for {
+		m.mu.Lock()
+		queue := m.forwards[to]
+		if len(queue) == 0 {
+			delete(m.forwards, to)
+			m.mu.Unlock()
+			return
+		}
+		next := queue[0]
+		m.mu.Unlock()
+
+		m.deliver(next)
+
+		m.mu.Lock()
+		m.forwards[to] = m.forwards[to][1:]
+		m.mu.Unlock()
+	}
+}
+
+// deliver sends one forward's prompt, waiting for a busy target to finish
+// its turn first. A forward whose link has been removed in the meantime is
+// dropped. Failures the target does not record itself are noted on the
+// source.
+func (m *Manager) deliver(f queuedForward) {
+	var err error
+	for {
+		if !m.linked(f.link) {
+			m.settle(f.link, false)
+			return
+		}
+		err = f.pipe.sendPrompt(context.Background(), f.input, nil)
+		if !errors.Is(err, ErrBusy) {
+			break
+		}
+		time.Sleep(busyRetryInterval)
+	}
+
+	var refused refusedError
+	sent := !errors.As(err, &refused)
+	if m.settle(f.link, sent) {
+		f.pipe.From.AddOutput(fmt.Sprintf("⇒ Link to %s reached its limit of %d forwards", f.pipe.To.DisplayName(), f.link.MaxForwards))
+	}
+
+	var sendErr *SendError
+	if err != nil && !errors.As(err, &sendErr) && !errors.Is(err, context.Canceled) {
+		f.pipe.From.AddOutput(fmt.Sprintf("⇒ Forward to %s failed: %v", f.pipe.To.DisplayName(), err))
+	}
+}
+
+// settle takes a forward off its link's pending count, counting it against
+// the cap if it was sent. It reports whether that used up the link.
+func (m *Manager) settle(link *Link, sent bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	link.pending--
+	if !sent {
+		return false
+	}
+	link.Forwarded++
+	return link.Exhausted()
+}
+
+func (m *Manager) linked(link *Link) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return slices.Contains(m.links, link)
+}
@@ -0,0 +1,141 @@
+package session
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it holds or the test has waited too long.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLinkForwardsEachReplyToBusyTarget(t *testing.T) {
+	m := NewManager()
+	m.SetBackendFactory(func(s *Session) Backend {
+		b := NewFakeBackend()
+		if s.Name == "target" {
+			b.Delay = 20 * time.Millisecond
+		}
+		return b
+	})
+	source := m.CreateSession("source", m.DefaultConfig())
+	target := m.CreateSession("target", m.DefaultConfig())
+	if err := m.AddLink(source, target, 0, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Keep the target busy while the source finishes several turns.
+	go target.SendInput("one two three four five six seven eight")
+	waitFor(t, "the target to be busy", target.Busy)
+	for _, turn := range []string{"turn 0", "turn 1", "turn 2"} {
+		if err := source.SendInput(turn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	forwarded := func() []string {
+		var prompts []string
+		for _, entry := range target.GetTranscript() {
+			if entry.Role == RoleUser && entry.SessionID == source.ID {
+				prompts = append(prompts, entry.Text())
+			}
+		}
+		return prompts
+	}
+	waitFor(t, "three forwards", func() bool { return len(forwarded()) == 3 && !target.Busy() })
+
+	// The replies arrive in the order the source finished them.
+	got := forwarded()
+	want := []string{
+		"Claude response to: turn 0",
+		"Claude response to: turn 1",
+		"Claude response to: turn 2",
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("forward %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLinkCountsOnlySentForwards(t *testing.T) {
+	m := NewManager()
+	source := m.CreateSession("source", m.DefaultConfig())
+	target := m.CreateSession("target", m.DefaultConfig())
+	broken := m.CreateSession("broken", m.DefaultConfig())
+	// Rendering fails: the template names a field pipes do not have.
+	if err := m.AddLink(source, broken, 1, "{{.Missing}}"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddLink(source, target, 1, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := target.Stop("paused"); err != nil {
+		t.Fatal(err)
+	}
+
+	settled := func() bool {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		for _, link := range m.links {
+			if link.pending > 0 {
+				return false
+			}
+		}
+		return true
+	}
+	forwarded := func() []int {
+		var counts []int
+		for _, link := range m.Links() {
+			counts = append(counts, link.Forwarded)
+		}
+		return counts
+	}
+
+	// Neither a forward that fails to render nor one a stopped target
+	// refuses counts against the cap.
+	for _, turn := range []string{"turn 0", "turn 1"} {
+		if err := source.SendInput(turn); err != nil {
+			t.Fatal(err)
+		}
+		waitFor(t, "the forwards to settle", settled)
+	}
+	if got := forwarded(); !slices.Equal(got, []int{0, 0}) {
+		t.Errorf("forwarded = %v after failed forwards, want [0 0]", got)
+	}
+	var failures int
+	for _, line := range source.GetOutput() {
+		if strings.Contains(line, "⇒ Forward to") && strings.Contains(line, "failed") {
+			failures++
+		}
+	}
+	if failures != 4 {
+		t.Errorf("source noted %d failed forwards, want 4:\n%s", failures, strings.Join(source.GetOutput(), "\n"))
+	}
+
+	if err := target.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.SendInput("turn 2"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the forward to be delivered", func() bool { return settled() && !target.Busy() })
+	if got := forwarded(); !slices.Equal(got, []int{0, 1}) {
+		t.Errorf("forwarded = %v, want [0 1]", got)
+	}
+	output := strings.Join(source.GetOutput(), "\n")
+	if !strings.Contains(output, "reached its limit of 1 forwards") {
+		t.Errorf("source output does not note the exhausted link:\n%s", output)
+	}
+}
@@ -0,0 +1,250 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+type Manager struct {
+	// sessions keeps creation order; byID and byAlias index it.
+	sessions       []*Session
+	byID           map[string]*Session
+	byAlias        map[string]*Session
+	backendFactory BackendFactory
+	defaultConfig  SessionConfig
+	prices         PriceTable
+	budget         Budget
+	retry          RetryPolicy
+	journalDir     string
+	archiver       Archiver
+	links          []*Link
+	forwards       map[string][]queuedForward
+	scratchpads    map[string]*Scratchpad
+	mu             sync.RWMutex
+
+	subscribers map[int]chan Event
+	nextSubID   int
+	subMu       sync.Mutex
+}
+
+func NewManager() *Manager {
+	return &Manager{
+		sessions:      make([]*Session, 0),
+		byID:          make(map[string]*Session),
+		byAlias:       make(map[string]*Session),
+		defaultConfig: DefaultSessionConfig(),
+		prices:        DefaultPriceTable(),
+		retry:         DefaultRetryPolicy(),
+		forwards:      make(map[string][]queuedForward),
+		scratchpads:   make(map[string]*Scratchpad),
+		subscribers:   make(map[int]chan Event),
+	}
+}
+
+func (m *Manager) CreateSession(name string, config SessionConfig) *Session {
+	session := NewSession(name, config)
+	m.add(session)
+	return session
+}
+
+// add wires a session into the manager and announces it.
+func (m *Manager) add(session *Session) {
+	m.mu.Lock()
+	if m.backendFactory != nil {
+		session.backend = m.backendFactory(session)
+	}
+	session.notify = m.handleEvent
+	session.prices = m.prices
+	session.admit = m.admit
+	session.retry = m.retry
+	session.shared = m.sharedContext
+	m.sessions = append(m.sessions, session)
+	m.byID[session.ID] = session
+	if session.Alias != "" {
+		m.byAlias[session.Alias] = session
+	}
+	m.mu.Unlock()
+
+	m.publish(Event{Type: EventSessionCreated, SessionID: session.ID})
+	if err := m.journalSession(session); err != nil {
+		session.AddOutput("Journal unavailable: " + err.Error())
+	}
+}
+
+// SetBackendFactory sets how backends are built for sessions created from now
+// on. A nil factory falls back to the FakeBackend.
+func (m *Manager) SetBackendFactory(factory BackendFactory) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.backendFactory = factory
+}
+
+// DefaultConfig is the config new sessions start from when the caller has no
+// specific settings of its own.
+func (m *Manager) DefaultConfig() SessionConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.defaultConfig
+}
+
+func (m *Manager) SetDefaultConfig(config SessionConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.defaultConfig = config
+}
+
+// handleEvent is how sessions report changes to the manager. Events are
+// published first so subscribers see the usage that may trip a budget.
+func (m *Manager) handleEvent(event Event) {
+	m.publish(event)
+	switch event.Type {
+	case EventUsageUpdated:
+		m.checkBudget()
+	case EventTurnCompleted:
+		m.forward(event.SessionID)
+	}
+}
+
+// SetPriceTable sets the prices used to cost usage from now on, for existing
+// sessions as well as new ones.
+func (m *Manager) SetPriceTable(prices PriceTable) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prices = prices
+	for _, session := range m.sessions {
+		session.mu.Lock()
+		session.prices = prices
+		session.mu.Unlock()
+	}
+}
+
+// TotalUsage sums usage and cost across all sessions.
+func (m *Manager) TotalUsage() (Usage, float64) {
+	var (
+		total Usage
+		cost  float64
+	)
+	for _, session := range m.GetSessions() {
+		usage, sessionCost := session.GetUsage()
+		total.Add(usage)
+		cost += sessionCost
+	}
+	return total, cost
+}
+
+func (m *Manager) GetSessions() []*Session {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sessions := make([]*Session, len(m.sessions))
+	copy(sessions, m.sessions)
+	return sessions
+}
+
+func (m *Manager) GetSession(id string) *Session {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.byID[id]
+}
+
+// Resolve finds a session by ID, alias or unambiguous ID prefix.
+func (m *Manager) Resolve(ref string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if session, ok := m.byID[ref]; ok {
+		return session, nil
+	}
+	if session, ok := m.byAlias[strings.TrimPrefix(ref, "@")]; ok {
+		return session, nil
+	}
+
+	var match *Session
+	for id, session := range m.byID {
+		if ref != "" && strings.HasPrefix(id, ref) {
+			if match != nil {
+				return nil, fmt.Errorf("session reference %q is ambiguous", ref)
+			}
+			match = session
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, ref)
+	}
+	return match, nil
+}
+
+// SetAlias gives a session a short, unique, user-chosen name that can be used
+// wherever a session ID is accepted. An empty alias removes it.
+func (m *Manager) SetAlias(id, alias string) error {
+	alias = strings.TrimPrefix(strings.TrimSpace(alias), "@")
+	if strings.ContainsAny(alias, " \t\n|") {
+		return fmt.Errorf("alias %q must not contain spaces or '|'", alias)
+	}
+
+	m.mu.Lock()
+	session, ok := m.byID[id]
+	if !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	if other, taken := m.byAlias[alias]; taken && other != session {
+		m.mu.Unlock()
+		return fmt.Errorf("alias %q is already used by session %s", alias, other.ID)
+	}
+	if _, clash := m.byID[alias]; clash {
+		m.mu.Unlock()
+		return fmt.Errorf("alias %q is a session ID", alias)
+	}
+
+	session.mu.Lock()
+	delete(m.byAlias, session.Alias)
+	session.Alias = alias
+	session.journalMeta()
+	session.mu.Unlock()
+	if alias != "" {
+		m.byAlias[alias] = session
+	}
+	m.mu.Unlock()
+
+	m.publish(Event{Type: EventSessionUpdated, SessionID: id})
+	return nil
+}
+
+func (m *Manager) RemoveSession(id string) bool {
+	m.mu.Lock()
+	removed, ok := m.byID[id]
+	if ok {
+		delete(m.byID, id)
+		delete(m.byAlias, removed.Alias)
+		for i, session := range m.sessions {
+			if session == removed {
+				m.sessions = append(m.sessions[:i], m.sessions[i+1:]...)
+				break
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+	m.removeLinks(func(link *Link) bool { return link.From == id || link.To == id })
+	// A session that cannot be archived is still removed; its journal
+	// remains as a record of it.
+	_ = m.archive(removed)
+	removed.Close()
+	removed.mu.Lock()
+	removed.notify = nil
+	journal := removed.journal
+	removed.journal = nil
+	removed.mu.Unlock()
+	journal.close(true)
+	m.publish(Event{Type: EventSessionRemoved, SessionID: id})
+	return true
+}
@@ -0,0 +1,63 @@
+package session
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetAlias(t *testing.T) {
+	m := NewManager()
+	a := m.CreateSession("a", m.DefaultConfig())
+	b := m.CreateSession("b", m.DefaultConfig())
+	if err := m.SetAlias(a.ID, "@rev"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		alias   string
+		wantErr string
+	}{
+		{name: "taken", id: b.ID, alias: "rev", wantErr: `alias "rev" is already used by session ` + a.ID},
+		{name: "taken with an @", id: b.ID, alias: "@rev", wantErr: `alias "rev" is already used`},
+		{name: "a session ID", id: b.ID, alias: a.ID, wantErr: "is a session ID"},
+		{name: "spaces", id: b.ID, alias: "my rev", wantErr: "must not contain spaces"},
+		{name: "unknown session", id: "nope", alias: "x", wantErr: "session not found: nope"},
+		{name: "same session again", id: a.ID, alias: "rev"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.SetAlias(tt.id, tt.alias)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+	if b.GetAlias() != "" {
+		t.Errorf("b got alias %q from a failed SetAlias", b.GetAlias())
+	}
+
+	// Moving the alias to another session frees the old one.
+	if err := m.SetAlias(a.ID, "old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetAlias(b.ID, "rev"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := m.Resolve("@rev"); err != nil || got != b {
+		t.Errorf("@rev resolves to %v (%v), want b", got, err)
+	}
+	if err := m.SetAlias(a.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Resolve("@old"); err == nil {
+		t.Error("a removed alias still resolves")
+	}
+}
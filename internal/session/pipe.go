@@ -0,0 +1,185 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// SelectionKind says how much of a source session a pipe sends.
+type SelectionKind int
+
+const (
+	// SelectLastReply is the text of the last assistant reply.
+	SelectLastReply SelectionKind = iota
+	// SelectLastMessages is the last N prompts and replies.
+	SelectLastMessages
+	// SelectTranscript is every prompt and reply.
+	SelectTranscript
+)
+
+type Selection struct {
+	Kind SelectionKind
+	N    int
+}
+
+func (sel Selection) String() string {
+	switch sel.Kind {
+	case SelectLastMessages:
+		return fmt.Sprintf("last %d", sel.N)
+	case SelectTranscript:
+		return "all"
+	default:
+		return "reply"
+	}
+}
+
+// ParseSelection reads "reply", "last N" or "all". An empty string is the
+// last reply.
+func ParseSelection(text string) (Selection, error) {
+	fields := strings.Fields(strings.ToLower(text))
+	switch {
+	case len(fields) == 0 || len(fields) == 1 && fields[0] == "reply":
+		return Selection{Kind: SelectLastReply}, nil
+	case len(fields) == 1 && fields[0] == "all":
+		return Selection{Kind: SelectTranscript}, nil
+	case len(fields) == 2 && fields[0] == "last":
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n < 1 {
+			return Selection{}, fmt.Errorf("invalid message count %q", fields[1])
+		}
+		return Selection{Kind: SelectLastMessages, N: n}, nil
+	}
+	return Selection{}, fmt.Errorf("unknown selection %q (want reply, last N or all)", text)
+}
+
+// Select returns the part of the transcript sel describes. Prompts and
+// replies other than a lone last reply are labelled with their role.
+func (s *Session) Select(sel Selection) (string, error) {
+	var messages []Entry
+	for _, entry := range s.GetTranscript() {
+		if (entry.Role == RoleUser || entry.Role == RoleAssistant) && entry.Text() != "" {
+			messages = append(messages, entry)
+		}
+	}
+
+	switch sel.Kind {
+	case SelectLastReply:
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].Role == RoleAssistant {
+				return messages[i].Text(), nil
+			}
+		}
+		return "", fmt.Errorf("%s has no reply to pipe", s.DisplayName())
+	case SelectLastMessages:
+		if len(messages) > sel.N {
+			messages = messages[len(messages)-sel.N:]
+		}
+	}
+	if len(messages) == 0 {
+		return "", fmt.Errorf("%s has no messages to pipe", s.DisplayName())
+	}
+
+	parts := make([]string, len(messages))
+	for i, entry := range messages {
+		label := "User"
+		if entry.Role == RoleAssistant {
+			label = "Assistant"
+		}
+		parts[i] = label + ": " + entry.Text()
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// PipeData is what a pipe template sees.
+type PipeData struct {
+	Output   string
+	Source   string
+	SourceID string
+}
+
+// Pipe describes sending part of one session to another as its next prompt.
+// Template, if set, is a text/template wrapping the selection, as in
+// "Review this:\n\n{{.Output}}".
+type Pipe struct {
+	From      *Session
+	To        *Session
+	Selection Selection
+	Template  string
+}
+
+// ParsePipe reads "<source> [selection] | <target>", where sessions are any
+// reference Resolve accepts and the selection is as for ParseSelection.
+func (m *Manager) ParsePipe(expr string) (Pipe, error) {
+	left, right, ok := strings.Cut(expr, "|")
+	if !ok {
+		return Pipe{}, errors.New("a pipe looks like: <source> [reply|last N|all] | <target>")
+	}
+	fields := strings.Fields(left)
+	if len(fields) == 0 || strings.TrimSpace(right) == "" {
+		return Pipe{}, errors.New("a pipe needs both a source and a target")
+	}
+
+	from, err := m.Resolve(fields[0])
+	if err != nil {
+		return Pipe{}, err
+	}
+	to, err := m.Resolve(strings.TrimSpace(right))
+	if err != nil {
+		return Pipe{}, err
+	}
+	sel, err := ParseSelection(strings.Join(fields[1:], " "))
+	if err != nil {
+		return Pipe{}, err
+	}
+	return Pipe{From: from, To: to, Selection: sel}, nil
+}
+
+// Prompt renders the prompt the pipe sends to its target.
+func (p Pipe) Prompt() (string, error) {
+	if p.From == nil || p.To == nil {
+		return "", errors.New("a pipe needs both a source and a target")
+	}
+	if p.From == p.To {
+		return "", errors.New("a session cannot pipe into itself")
+	}
+	output, err := p.From.Select(p.Selection)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(p.Template) == "" {
+		return output, nil
+	}
+
+	tmpl, err := template.New("pipe").Option("missingkey=error").Parse(p.Template)
+	if err != nil {
+		return "", fmt.Errorf("pipe template: %w", err)
+	}
+	var prompt strings.Builder
+	data := PipeData{Output: output, Source: p.From.DisplayName(), SourceID: p.From.ID}
+	if err := tmpl.Execute(&prompt, data); err != nil {
+		return "", fmt.Errorf("pipe template: %w", err)
+	}
+	return prompt.String(), nil
+}
+
+// Send delivers the pipe's prompt to its target and waits for the reply. The
+// prompt is recorded in the target's transcript as coming from the source.
+func (p Pipe) Send(ctx context.Context, onChunk func(Chunk)) error {
+	input, err := p.Prompt()
+	if err != nil {
+		return err
+	}
+	return p.sendPrompt(ctx, input, onChunk)
+}
+
+// sendPrompt delivers input, already rendered by Prompt, to the target.
+func (p Pipe) sendPrompt(ctx context.Context, input string, onChunk func(Chunk)) error {
+	prompt := TextEntry(RoleUser, input)
+	prompt.SessionID = p.From.ID
+	prompt.Source = p.From.DisplayName()
+	return p.To.send(ctx, input, &prompt, onChunk)
+}
@@ -0,0 +1,182 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how transient backend errors are retried. Delays grow
+// exponentially from BaseDelay up to MaxDelay with random jitter, and never
+// undercut a server's retry-after.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   time.Second,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// Backoff returns how long to wait before the attempt after the given one.
+func (p RetryPolicy) Backoff(attempt int, retryAfter time.Duration) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	// Equal jitter: half fixed, half random, so retries from many sessions
+	// spread out without collapsing to zero.
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+	if retryAfter > delay {
+		delay = retryAfter
+	}
+	return delay
+}
+
+type ErrorKind string
+
+const (
+	ErrorRateLimited ErrorKind = "rate_limited"
+	ErrorOverloaded  ErrorKind = "overloaded"
+	ErrorServer      ErrorKind = "server"
+	ErrorNetwork     ErrorKind = "network"
+	ErrorRequest     ErrorKind = "request"
+)
+
+// SendError is the error a Session records when a request finally fails.
+type SendError struct {
+	Kind       ErrorKind
+	StatusCode int
+	Message    string
+	Attempts   int
+	Retryable  bool
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *SendError) Error() string {
+	msg := fmt.Sprintf("%s: %s", e.Kind, e.Message)
+	if e.Attempts > 1 {
+		msg += fmt.Sprintf(" (after %d attempts)", e.Attempts)
+	}
+	return msg
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+// classifyError decides what kind of failure err is and whether retrying it
+// could help.
+func classifyError(err error) *SendError {
+	sendErr := &SendError{Kind: ErrorRequest, Message: err.Error(), Err: err}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		sendErr.StatusCode = apiErr.StatusCode
+		sendErr.Message = apiErr.Message
+		sendErr.RetryAfter = apiErr.RetryAfter
+		switch {
+		case apiErr.StatusCode == http.StatusTooManyRequests || apiErr.Type == "rate_limit_error":
+			sendErr.Kind = ErrorRateLimited
+		case apiErr.StatusCode == 529 || apiErr.Type == "overloaded_error":
+			sendErr.Kind = ErrorOverloaded
+		case apiErr.StatusCode >= 500 || apiErr.Type == "api_error":
+			sendErr.Kind = ErrorServer
+		}
+		sendErr.Retryable = sendErr.Kind != ErrorRequest
+		return sendErr
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.ErrUnexpectedEOF) {
+		sendErr.Kind = ErrorNetwork
+		sendErr.Retryable = true
+	}
+	return sendErr
+}
+
+// waitRetry sleeps until the next attempt while showing StatusConnecting with
+// the time of that attempt, so the UI can count down.
+func (s *Session) waitRetry(ctx context.Context, sendErr *SendError, delay time.Duration) error {
+	s.mu.Lock()
+	s.RetryAt = time.Now().Add(delay)
+	maxAttempts := s.retry.MaxAttempts
+	s.mu.Unlock()
+
+	s.AddOutput(fmt.Sprintf("⟳ %s, retrying in %s (attempt %d/%d)",
+		sendErr.Message, delay.Round(time.Millisecond), sendErr.Attempts+1, maxAttempts))
+	s.SetStatus(StatusConnecting)
+
+	timer := time.NewTimer(delay)
+	defer func() {
+		timer.Stop()
+		s.mu.Lock()
+		s.RetryAt = time.Time{}
+		s.mu.Unlock()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RetryCountdown returns how long until the next retry, or zero when the
+// session is not waiting to retry.
+func (s *Session) RetryCountdown() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.RetryAt.IsZero() {
+		return 0
+	}
+	if remaining := time.Until(s.RetryAt); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+func (s *Session) GetLastError() *SendError {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.LastError
+}
+
+// RetryLast resends the last prompt without adding it to the transcript again.
+func (s *Session) RetryLast(ctx context.Context, onChunk func(Chunk)) error {
+	s.mu.RLock()
+	prompt := s.LastPrompt
+	s.mu.RUnlock()
+
+	if prompt == "" {
+		return errors.New("no prompt to retry")
+	}
+	return s.send(ctx, prompt, nil, onChunk)
+}
+
+func (m *Manager) SetRetryPolicy(policy RetryPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.retry = policy
+	for _, session := range m.sessions {
+		session.mu.Lock()
+		session.retry = policy
+		session.mu.Unlock()
+	}
+}
@@ -0,0 +1,138 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyBackend fails its first failures requests with err, after streaming
+// part of a reply, and answers the rest.
+type flakyBackend struct {
+	failures int
+	err      error
+
+	mu    sync.Mutex
+	calls []time.Time
+}
+
+func (b *flakyBackend) Send(ctx context.Context, req Request, emit func(Chunk)) error {
+	b.mu.Lock()
+	b.calls = append(b.calls, time.Now())
+	failed := len(b.calls) <= b.failures
+	b.mu.Unlock()
+
+	if failed {
+		emit(Chunk{Type: ChunkText, Text: "partial"})
+		return b.err
+	}
+	emit(Chunk{Type: ChunkText, Text: "ok"})
+	return nil
+}
+
+func (b *flakyBackend) Cancel()                    {}
+func (b *flakyBackend) Close() error               { return nil }
+func (b *flakyBackend) Capabilities() Capabilities { return Capabilities{} }
+
+func (b *flakyBackend) attempts() []time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls
+}
+
+func flakySession(backend *flakyBackend, maxAttempts int) *Session {
+	m := NewManager()
+	m.SetBackendFactory(func(*Session) Backend { return backend })
+	m.SetRetryPolicy(RetryPolicy{MaxAttempts: maxAttempts, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+	return m.CreateSession("flaky", m.DefaultConfig())
+}
+
+func TestSendRetries(t *testing.T) {
+	overloaded := &APIError{StatusCode: 529, Type: "overloaded_error", Message: "Overloaded"}
+	tests := []struct {
+		name     string
+		failures int
+		err      error
+		wantKind ErrorKind // empty when the send succeeds
+		wantSent int
+	}{
+		{name: "recovers", failures: 2, err: overloaded, wantSent: 3},
+		{name: "network error", failures: 1, err: io.ErrUnexpectedEOF, wantSent: 2},
+		{name: "gives up", failures: 5, err: overloaded, wantKind: ErrorOverloaded, wantSent: 3},
+		{
+			name:     "not retryable",
+			failures: 5,
+			err:      &APIError{StatusCode: 400, Type: "invalid_request_error", Message: "max_tokens is too large"},
+			wantKind: ErrorRequest,
+			wantSent: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &flakyBackend{failures: tt.failures, err: tt.err}
+			sess := flakySession(backend, 3)
+			err := sess.Send(context.Background(), "hi", nil)
+
+			if got := len(backend.attempts()); got != tt.wantSent {
+				t.Errorf("sent %d times, want %d", got, tt.wantSent)
+			}
+			if tt.wantKind == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				// The partial replies of failed attempts are dropped.
+				if reply, _ := sess.Select(Selection{Kind: SelectLastReply}); reply != "ok" {
+					t.Errorf("reply = %q, want ok", reply)
+				}
+				return
+			}
+			var sendErr *SendError
+			if !errors.As(err, &sendErr) || sendErr.Kind != tt.wantKind || sendErr.Attempts != tt.wantSent {
+				t.Fatalf("err = %#v, want %s after %d attempts", err, tt.wantKind, tt.wantSent)
+			}
+			if sess.GetStatus() != StatusError || sess.GetLastError() != sendErr {
+				t.Errorf("status %s, last error %v", sess.GetStatus(), sess.GetLastError())
+			}
+		})
+	}
+}
+
+func TestSendWaitsForRetryAfter(t *testing.T) {
+	retryAfter := 60 * time.Millisecond
+	backend := &flakyBackend{failures: 1, err: &APIError{StatusCode: 429, Type: "rate_limit_error", Message: "slow down", RetryAfter: retryAfter}}
+	sess := flakySession(backend, 3)
+	if err := sess.Send(context.Background(), "hi", nil); err != nil {
+		t.Fatal(err)
+	}
+	calls := backend.attempts()
+	if len(calls) != 2 {
+		t.Fatalf("sent %d times, want 2", len(calls))
+	}
+	if gap := calls[1].Sub(calls[0]); gap < retryAfter {
+		t.Errorf("retried after %s, before the server's retry-after of %s", gap, retryAfter)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	tests := []struct {
+		attempt    int
+		retryAfter time.Duration
+		min, max   time.Duration
+	}{
+		{attempt: 1, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{attempt: 3, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		{attempt: 10, min: 500 * time.Millisecond, max: time.Second},
+		{attempt: 1, retryAfter: 5 * time.Second, min: 5 * time.Second, max: 5 * time.Second},
+	}
+	for _, tt := range tests {
+		for range 20 {
+			if got := policy.Backoff(tt.attempt, tt.retryAfter); got < tt.min || got > tt.max {
+				t.Errorf("Backoff(%d, %s) = %s, want between %s and %s", tt.attempt, tt.retryAfter, got, tt.min, tt.max)
+			}
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrScratchpadNotFound = errors.New("scratchpad not found")
+
+// ScratchpadVersion is one write to a scratchpad. Author is the ID of the
+// session that wrote it, empty for the user; AuthorName is kept so the
+// history still reads well once that session is gone.
+type ScratchpadVersion struct {
+	Version    int       `json:"version"`
+	Content    string    `json:"content"`
+	Author     string    `json:"author,omitempty"`
+	AuthorName string    `json:"author_name"`
+	Time       time.Time `json:"time"`
+}
+
+// Scratchpad is named context shared between sessions. Every write is kept
+// as a new version.
+type Scratchpad struct {
+	Name     string              `json:"name"`
+	Versions []ScratchpadVersion `json:"versions"`
+}
+
+// Current returns the latest version, or the zero version for a scratchpad
+// that was never written.
+func (p Scratchpad) Current() ScratchpadVersion {
+	if len(p.Versions) == 0 {
+		return ScratchpadVersion{}
+	}
+	return p.Versions[len(p.Versions)-1]
+}
+
+func validScratchpadName(name string) error {
+	if name == "" || strings.ContainsAny(name, " \t\n|,") {
+		return fmt.Errorf("scratchpad name %q must be non-empty without spaces, ',' or '|'", name)
+	}
+	return nil
+}
+
+// CreateScratchpad adds an empty scratchpad. Creating one that exists is
+// not an error.
+func (m *Manager) CreateScratchpad(name string) error {
+	if err := validScratchpadName(name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	if _, ok := m.scratchpads[name]; !ok {
+		m.scratchpads[name] = &Scratchpad{Name: name}
+	}
+	m.mu.Unlock()
+
+	m.publish(Event{Type: EventScratchpadUpdated, Text: name})
+	return nil
+}
+
+// WriteScratchpad replaces a scratchpad's content, creating it if needed,
+// and returns the new version. author is nil for edits made by the user.
+// Writing the current content again does not add a version.
+func (m *Manager) WriteScratchpad(name, content string, author *Session) (ScratchpadVersion, error) {
+	if err := validScratchpadName(name); err != nil {
+		return ScratchpadVersion{}, err
+	}
+	version := ScratchpadVersion{Content: content, AuthorName: "user", Time: time.Now()}
+	if author != nil {
+		version.Author, version.AuthorName = author.ID, author.DisplayName()
+	}
+
+	m.mu.Lock()
+	pad, ok := m.scratchpads[name]
+	if !ok {
+		pad = &Scratchpad{Name: name}
+		m.scratchpads[name] = pad
+	}
+	if current := pad.Current(); current.Version > 0 && current.Content == content {
+		m.mu.Unlock()
+		return current, nil
+	}
+	version.Version = len(pad.Versions) + 1
+	pad.Versions = append(pad.Versions, version)
+	m.mu.Unlock()
+
+	m.publish(Event{Type: EventScratchpadUpdated, SessionID: version.Author, Text: name})
+	return version, nil
+}
+
+// GetScratchpad returns a copy of the named scratchpad.
+func (m *Manager) GetScratchpad(name string) (Scratchpad, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pad, ok := m.scratchpads[name]
+	if !ok {
+		return Scratchpad{}, false
+	}
+	return Scratchpad{Name: pad.Name, Versions: slices.Clone(pad.Versions)}, true
+}
+
+// Scratchpads returns copies of every scratchpad, sorted by name.
+func (m *Manager) Scratchpads() []Scratchpad {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pads := make([]Scratchpad, 0, len(m.scratchpads))
+	for _, pad := range m.scratchpads {
+		pads = append(pads, Scratchpad{Name: pad.Name, Versions: slices.Clone(pad.Versions)})
+	}
+	sort.Slice(pads, func(i, j int) bool { return pads[i].Name < pads[j].Name })
+	return pads
+}
+
+// AttachScratchpad makes the named scratchpad part of every prompt the
+// session sends, creating it if needed.
+func (m *Manager) AttachScratchpad(s *Session, name string) error {
+	if err := m.CreateScratchpad(name); err != nil {
+		return err
+	}
+	config := s.GetConfig()
+	if slices.Contains(config.Scratchpads, name) {
+		return nil
+	}
+	config.Scratchpads = append(slices.Clone(config.Scratchpads), name)
+	s.SetConfig(config)
+
+	m.publish(Event{Type: EventSessionUpdated, SessionID: s.ID})
+	return nil
+}
+
+// DetachScratchpad stops sending the named scratchpad with the session's
+// prompts, reporting whether it was attached.
+func (m *Manager) DetachScratchpad(s *Session, name string) bool {
+	config := s.GetConfig()
+	i := slices.Index(config.Scratchpads, name)
+	if i < 0 {
+		return false
+	}
+	config.Scratchpads = slices.Delete(slices.Clone(config.Scratchpads), i, i+1)
+	s.SetConfig(config)
+
+	m.publish(Event{Type: EventSessionUpdated, SessionID: s.ID})
+	return true
+}
+
+// sharedContext renders the session's scratchpads for the start of its next
+// prompt and, for backends that run tools, the tools to read and write them.
+func (m *Manager) sharedContext(s *Session, clientTools bool) (string, []Tool) {
+	names := s.GetConfig().Scratchpads
+	if len(names) == 0 {
+		return "", nil
+	}
+
+	var preamble strings.Builder
+	for _, name := range names {
+		pad, ok := m.GetScratchpad(name)
+		current := pad.Current()
+		if !ok || strings.TrimSpace(current.Content) == "" {
+			continue
+		}
+		fmt.Fprintf(&preamble, "<scratchpad name=%q version=\"%d\">\n%s\n</scratchpad>\n\n",
+			name, current.Version, strings.TrimRight(current.Content, "\n"))
+	}
+	if !clientTools {
+		return preamble.String(), nil
+	}
+	return preamble.String(), m.scratchpadTools(s, names)
+}
+
+var (
+	scratchpadReadSchema = json.RawMessage(`{"type":"object","properties":{"name":{"type":"string","description":"Scratchpad name"}},"required":["name"]}`)
+
+	scratchpadWriteSchema = json.RawMessage(`{"type":"object","properties":{"name":{"type":"string","description":"Scratchpad name"},"content":{"type":"string","description":"The scratchpad's new content, replacing the old"}},"required":["name","content"]}`)
+)
+
+// scratchpadTools lets a session read and rewrite the scratchpads it is
+// attached to. Writes are recorded with the session as their author.
+func (m *Manager) scratchpadTools(s *Session, names []string) []Tool {
+	attached := strings.Join(names, ", ")
+	parse := func(input json.RawMessage) (string, string, error) {
+		var args struct {
+			Name    string `json:"name"`
+			Content string `json:"content"`
+		}
+		if err := json.Unmarshal(input, &args); err != nil {
+			return "", "", fmt.Errorf("invalid input: %w", err)
+		}
+		if !slices.Contains(names, args.Name) {
+			return "", "", fmt.Errorf("%w: %q (attached: %s)", ErrScratchpadNotFound, args.Name, attached)
+		}
+		return args.Name, args.Content, nil
+	}
+
+	return []Tool{
+		{
+			Name:        "scratchpad_read",
+			Description: "Read the current content of a shared scratchpad. Attached scratchpads: " + attached,
+			InputSchema: scratchpadReadSchema,
+			Run: func(input json.RawMessage) (string, error) {
+				name, _, err := parse(input)
+				if err != nil {
+					return "", err
+				}
+				pad, _ := m.GetScratchpad(name)
+				current := pad.Current()
+				return fmt.Sprintf("version %d by %s:\n%s", current.Version, current.AuthorName, current.Content), nil
+			},
+		},
+		{
+			Name:        "scratchpad_write",
+			Description: "Replace the content of a shared scratchpad that other sessions also read. Attached scratchpads: " + attached,
+			InputSchema: scratchpadWriteSchema,
+			Run: func(input json.RawMessage) (string, error) {
+				name, content, err := parse(input)
+				if err != nil {
+					return "", err
+				}
+				version, err := m.WriteScratchpad(name, content, s)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("saved %s as version %d", name, version.Version), nil
+			},
+		},
+	}
+}
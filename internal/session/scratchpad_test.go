@@ -0,0 +1,265 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestScratchpadVersions(t *testing.T) {
+	m := NewManager()
+	author := m.CreateSession("writer", m.DefaultConfig())
+
+	if _, err := m.WriteScratchpad("bad name", "x", nil); err == nil {
+		t.Error("a name with a space was accepted")
+	}
+	for _, write := range []struct {
+		content string
+		author  *Session
+	}{
+		{"first", nil},
+		{"second", author},
+		{"second", nil}, // unchanged: no new version
+		{"third", nil},
+	} {
+		if _, err := m.WriteScratchpad("plan", write.content, write.author); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pad, ok := m.GetScratchpad("plan")
+	if !ok {
+		t.Fatal("the scratchpad was not created")
+	}
+	type version struct {
+		n               int
+		content, author string
+		authorName      string
+	}
+	var got []version
+	for _, v := range pad.Versions {
+		got = append(got, version{v.Version, v.Content, v.Author, v.AuthorName})
+	}
+	want := []version{
+		{1, "first", "", "user"},
+		{2, "second", author.ID, "writer"},
+		{3, "third", "", "user"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("versions = %+v, want %+v", got, want)
+	}
+
+	// The copy handed out does not share the history.
+	pad.Versions[0].Content = "changed"
+	if again, _ := m.GetScratchpad("plan"); again.Versions[0].Content != "first" {
+		t.Error("changing a returned scratchpad changed the manager's")
+	}
+}
+
+func TestScratchpadAttachAndContext(t *testing.T) {
+	m := NewManager()
+	sess := m.CreateSession("reader", m.DefaultConfig())
+
+	if err := m.AttachScratchpad(sess, "plan"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AttachScratchpad(sess, "plan"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AttachScratchpad(sess, "empty"); err != nil {
+		t.Fatal(err)
+	}
+	if got := sess.GetConfig().Scratchpads; !slices.Equal(got, []string{"plan", "empty"}) {
+		t.Fatalf("attached = %v, want [plan empty]", got)
+	}
+	if _, err := m.WriteScratchpad("plan", "Ship on Friday.\n", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The fake backend echoes what it was sent: the scratchpad, then the
+	// prompt. Empty scratchpads are left out.
+	if err := sess.SendInput("When?"); err != nil {
+		t.Fatal(err)
+	}
+	transcript := sess.GetTranscript()
+	if prompt := transcript[0].Text(); prompt != "When?" {
+		t.Errorf("transcript prompt = %q, want it without the scratchpad", prompt)
+	}
+	want := `Claude response to: <scratchpad name="plan" version="1"> Ship on Friday. </scratchpad>  When?`
+	if reply := transcript[1].Text(); reply != want {
+		t.Errorf("reply = %q, want %q", reply, want)
+	}
+
+	if !m.DetachScratchpad(sess, "plan") {
+		t.Error("detaching an attached scratchpad reported false")
+	}
+	if m.DetachScratchpad(sess, "plan") {
+		t.Error("detaching it again reported true")
+	}
+	if err := sess.SendInput("Now?"); err != nil {
+		t.Fatal(err)
+	}
+	if reply := sess.GetTranscript()[3].Text(); reply != "Claude response to: Now?" {
+		t.Errorf("reply after detaching = %q", reply)
+	}
+}
+
+// apiCall is the part of a Messages API request the tool tests look at.
+type apiCall struct {
+	Messages []struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	} `json:"messages"`
+	Tools []struct {
+		Name string `json:"name"`
+	} `json:"tools"`
+}
+
+// sequenceServer answers the nth request with the recorded event stream in
+// testdata/api/names[n], repeating the last one, and keeps every request.
+func sequenceServer(t *testing.T, names ...string) (*httptest.Server, func() []apiCall) {
+	t.Helper()
+	var streams [][]byte
+	for _, name := range names {
+		stream, err := os.ReadFile(filepath.Join("testdata", "api", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		streams = append(streams, stream)
+	}
+
+	var (
+		mu    sync.Mutex
+		calls []apiCall
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var call apiCall
+		if err := json.Unmarshal(body, &call); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		calls = append(calls, call)
+		stream := streams[min(len(calls), len(streams))-1]
+		mu.Unlock()
+		w.Header().Set("content-type", "text/event-stream")
+		w.Write(stream)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []apiCall {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(calls)
+	}
+}
+
+func TestScratchpadToolsOverAPI(t *testing.T) {
+	server, calls := sequenceServer(t, "tool_use.sse", "tool_done.sse")
+	m := NewManager()
+	m.SetBackendFactory(func(*Session) Backend {
+		backend := NewAPIBackend("test-key")
+		backend.BaseURL = server.URL
+		return backend
+	})
+	sess := m.CreateSession("planner", m.DefaultConfig())
+	if err := m.AttachScratchpad(sess, "plan"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.WriteScratchpad("plan", "v1: draft", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sess.SendInput("Finish the plan."); err != nil {
+		t.Fatal(err)
+	}
+
+	// The write the model asked for is a new version by this session.
+	pad, _ := m.GetScratchpad("plan")
+	current := pad.Current()
+	if current.Version != 2 || current.Content != "v2: ship it" || current.Author != sess.ID || current.AuthorName != "planner" {
+		t.Errorf("current version = %+v, want version 2 by the session", current)
+	}
+
+	got := calls()
+	if len(got) != 2 {
+		t.Fatalf("made %d requests, want 2", len(got))
+	}
+	var tools []string
+	for _, tool := range got[0].Tools {
+		tools = append(tools, tool.Name)
+	}
+	if !slices.Equal(tools, []string{"scratchpad_read", "scratchpad_write"}) {
+		t.Errorf("tools = %v", tools)
+	}
+	var prompt string
+	if err := json.Unmarshal(got[0].Messages[0].Content, &prompt); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(prompt, `<scratchpad name="plan" version="1">`) || !strings.HasSuffix(prompt, "Finish the plan.") {
+		t.Errorf("first prompt = %q, want the scratchpad in front", prompt)
+	}
+
+	// The second request carries the tool call and its result.
+	messages := got[1].Messages
+	if len(messages) != 3 || messages[1].Role != "assistant" || messages[2].Role != "user" {
+		t.Fatalf("second request messages = %+v", messages)
+	}
+	var results []apiContent
+	if err := json.Unmarshal(messages[2].Content, &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Type != "tool_result" || results[0].ToolUseID != "toolu_01" || results[0].Content != "saved plan as version 2" || results[0].IsError {
+		t.Errorf("tool results = %+v", results)
+	}
+
+	// The transcript shows the call and its result between the replies.
+	var blocks []string
+	for _, entry := range sess.GetTranscript()[1:] {
+		for _, block := range entry.Blocks {
+			blocks = append(blocks, string(block.Type)+": "+block.Text)
+		}
+	}
+	wantBlocks := []string{
+		"text: Updating the plan.",
+		`tool_use: {"name": "plan", "content": "v2: ship it"}`,
+		"tool_result: saved plan as version 2",
+		"text: Done.",
+	}
+	if !slices.Equal(blocks, wantBlocks) {
+		t.Errorf("transcript blocks = %q, want %q", blocks, wantBlocks)
+	}
+}
+
+func TestAPIBackendGivesUpOnEndlessTools(t *testing.T) {
+	server, calls := sequenceServer(t, "tool_use.sse")
+	backend := NewAPIBackend("test-key")
+	backend.BaseURL = server.URL
+
+	writes := 0
+	req := Request{Input: "Loop.", Tools: []Tool{{
+		Name: "scratchpad_write",
+		Run: func(json.RawMessage) (string, error) {
+			writes++
+			return "ok", nil
+		},
+	}}}
+	err := backend.Send(context.Background(), req, func(Chunk) {})
+	if err == nil || !strings.Contains(err.Error(), "gave up after 20 rounds of tool calls") {
+		t.Fatalf("err = %v, want the tool loop cut off", err)
+	}
+	if n := len(calls()); n != maxToolRounds {
+		t.Errorf("made %d requests, want %d", n, maxToolRounds)
+	}
+	if writes != maxToolRounds-1 {
+		t.Errorf("ran the tool %d times, want %d", writes, maxToolRounds-1)
+	}
+}
@@ -1,12 +1,18 @@
 package session
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"strings"
 	"sync"
 	"time"
 )
 
+var (
+	ErrBusy    = errors.New("session is busy")
+	ErrStopped = errors.New("session stopped")
+)
+
 type Status int
 
 const (
@@ -15,6 +21,7 @@ const (
 	StatusConnecting
 	StatusError
 	StatusStopped
+	StatusKilled
 )
 
 func (s Status) String() string {
@@ -29,57 +36,230 @@ func (s Status) String() string {
 		return "error"
 	case StatusStopped:
 		return "stopped"
+	case StatusKilled:
+		return "killed"
 	default:
 		return "unknown"
 	}
 }
 
 type Session struct {
-	ID          string
-	Name        string
-	Status      Status
-	Output      []string
-	LastMessage string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	mu          sync.RWMutex
+	ID            string
+	Name          string
+	Alias         string
+	Status        Status
+	Config        SessionConfig
+	Transcript    []Entry
+	Usage         Usage
+	Cost          float64
+	BudgetWarning string
+	StopReason    string
+	LastPrompt    string
+	LastError     *SendError
+	RetryAt       time.Time
+	LastMessage   string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	backend       Backend
+	replying      bool
+	cancel        context.CancelFunc
+	notify        func(Event)
+	prices        PriceTable
+	admit         func() error
+	retry         RetryPolicy
+	shared        func(s *Session, clientTools bool) (string, []Tool)
+	journal       *Journal
+	mu            sync.RWMutex
 }
 
-func NewSession(name string) *Session {
+func NewSession(name string, config SessionConfig) *Session {
 	return &Session{
-		ID:        generateID(),
-		Name:      name,
-		Status:    StatusIdle,
-		Output:    make([]string, 0),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:         generateID(),
+		Name:       name,
+		Config:     config,
+		Status:     StatusIdle,
+		Transcript: make([]Entry, 0),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+		backend:    NewFakeBackend(),
+		prices:     DefaultPriceTable(),
+		retry:      DefaultRetryPolicy(),
+	}
+}
+
+func (s *Session) GetAlias() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Alias
+}
+
+// DisplayName is the session's name followed by its alias, if it has one.
+func (s *Session) DisplayName() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.Alias == "" {
+		return s.Name
+	}
+	return s.Name + " @" + s.Alias
+}
+
+func (s *Session) Backend() Backend {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.backend
+}
+
+// SetBackend replaces the session's backend, closing the previous one.
+func (s *Session) SetBackend(b Backend) {
+	s.mu.Lock()
+	old := s.backend
+	s.backend = b
+	s.mu.Unlock()
+
+	if old != nil && old != b {
+		old.Close()
 	}
 }
 
+// AddOutput appends a system message to the transcript.
 func (s *Session) AddOutput(text string) {
+	s.AddEntry(TextEntry(RoleSystem, text))
+}
+
+// AddEntry appends entry to the transcript, filling in its timestamp and
+// source session when they are unset.
+func (s *Session) AddEntry(entry Entry) {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	if entry.SessionID == "" {
+		entry.SessionID = s.ID
+	}
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.Transcript = append(s.Transcript, entry)
+	s.replying = false
+	s.journal.write(journalRecord{Type: recordEntry, Index: intPtr(len(s.Transcript) - 1), Entry: &entry})
+	s.touch()
+	s.mu.Unlock()
 
-	s.Output = append(s.Output, text)
-	s.LastMessage = text
+	s.emit(Event{Type: EventOutputAppended, SessionID: s.ID, Text: entry.Text()})
+}
+
+// appendReply adds a block to the assistant entry of the turn in progress,
+// opening a new entry when the last one is not that reply. Consecutive text
+// blocks are merged so streamed deltas form a single block.
+func (s *Session) appendReply(block ContentBlock) {
+	s.mu.Lock()
+	if !s.replying || len(s.Transcript) == 0 {
+		s.Transcript = append(s.Transcript, Entry{Role: RoleAssistant, Timestamp: time.Now(), SessionID: s.ID})
+		s.replying = true
+	}
+	entry := &s.Transcript[len(s.Transcript)-1]
+	if last := len(entry.Blocks) - 1; block.Type == BlockText && last >= 0 && entry.Blocks[last].Type == BlockText {
+		entry.Blocks[last].Text += block.Text
+	} else {
+		entry.Blocks = append(entry.Blocks, block)
+	}
+	s.journal.write(journalRecord{Type: recordDelta, Index: intPtr(len(s.Transcript) - 1), Block: &block})
+	s.touch()
+	s.mu.Unlock()
+
+	s.emit(Event{Type: EventOutputAppended, SessionID: s.ID, Text: block.Text})
+}
+
+// recordUsage attaches usage to the most recent assistant entry and adds it to
+// the session's running totals.
+func (s *Session) recordUsage(usage Usage) {
+	s.mu.Lock()
+	s.Usage.Add(usage)
+	s.Cost += s.prices.Cost(s.Config.Model, usage)
+	record := journalRecord{Type: recordUsage}
+	for i := len(s.Transcript) - 1; i >= 0; i-- {
+		if s.Transcript[i].Role == RoleAssistant {
+			total := usage
+			if s.Transcript[i].Usage != nil {
+				total.Add(*s.Transcript[i].Usage)
+			}
+			s.Transcript[i].Usage = &total
+			record.Index, record.EntryUsage = intPtr(i), &total
+			break
+		}
+	}
+	sessionUsage, cost := s.Usage, s.Cost
+	record.Usage, record.Cost = &sessionUsage, &cost
+	s.journal.write(record)
+	s.touch()
+	s.mu.Unlock()
+
+	s.emit(Event{Type: EventUsageUpdated, SessionID: s.ID})
+	s.checkBudget()
+}
+
+// GetUsage returns the session's total token usage and its cost in USD.
+func (s *Session) GetUsage() (Usage, float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Usage, s.Cost
+}
+
+// GetLastMessage returns the last line of the transcript.
+func (s *Session) GetLastMessage() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.LastMessage
+}
+
+// touch updates LastMessage and UpdatedAt. Callers must hold s.mu.
+func (s *Session) touch() {
 	s.UpdatedAt = time.Now()
+	if len(s.Transcript) == 0 {
+		return
+	}
+	if lines := s.Transcript[len(s.Transcript)-1].Lines(); len(lines) > 0 {
+		s.LastMessage = lines[len(lines)-1]
+	}
 }
 
-func (s *Session) GetOutput() []string {
+func (s *Session) GetTranscript() []Entry {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	output := make([]string, len(s.Output))
-	copy(output, s.Output)
-	return output
+	transcript := make([]Entry, len(s.Transcript))
+	copy(transcript, s.Transcript)
+	return transcript
 }
 
-func (s *Session) SetStatus(status Status) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// GetOutput renders the transcript as output pane lines.
+func (s *Session) GetOutput() []string {
+	return RenderLines(s.GetTranscript())
+}
 
+// SetStatus moves the session to status, refusing transitions the lifecycle
+// does not allow.
+func (s *Session) SetStatus(status Status) error {
+	s.mu.Lock()
+	previous := s.Status
+	if !canTransition(previous, status) {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s → %s", ErrInvalidTransition, previous, status)
+	}
 	s.Status = status
 	s.UpdatedAt = time.Now()
+	if previous != status {
+		record := journalRecord{Type: recordStatus, Status: &status}
+		if status == StatusStopped {
+			record.Reason = s.StopReason
+		}
+		s.journal.write(record)
+	}
+	s.mu.Unlock()
+
+	if previous != status {
+		s.emit(Event{Type: EventStatusChanged, SessionID: s.ID, Status: status})
+	}
+	return nil
 }
 
 func (s *Session) GetStatus() Status {
@@ -88,70 +268,271 @@ func (s *Session) GetStatus() Status {
 	return s.Status
 }
 
-func (s *Session) SendInput(input string) {
-	// For now, this is a placeholder that simulates Claude processing
-	// In a real implementation, this would send the input to Claude API
-	s.AddOutput("🤖 Processing your request...")
-	
-	// Simulate a Claude response
-	response := fmt.Sprintf("Claude response to: %s", strings.ReplaceAll(strings.TrimSpace(input), "\n", " "))
-	s.AddOutput(response)
+// SendInput sends input and waits for the reply.
+func (s *Session) SendInput(input string) error {
+	return s.Send(context.Background(), input, nil)
+}
+
+// Send runs one turn against the session's backend, moving the status from
+// StatusConnecting to StatusRunning when the first chunk arrives and then to
+// StatusIdle or StatusError. onChunk, if set, sees every chunk after the
+// session has recorded it. Cancelling ctx or calling Cancel aborts the turn.
+func (s *Session) Send(ctx context.Context, input string, onChunk func(Chunk)) error {
+	prompt := TextEntry(RoleUser, input)
+	return s.send(ctx, input, &prompt, onChunk)
+}
+
+// refusedError wraps the errors send returns before the prompt reaches the
+// transcript, telling a turn that never started from one that failed.
+type refusedError struct{ error }
+
+func (e refusedError) Unwrap() error { return e.error }
+
+// send runs one turn. prompt, when set, is added to the transcript first; a
+// retry leaves it nil because the prompt is already there.
+func (s *Session) send(ctx context.Context, input string, prompt *Entry, onChunk func(Chunk)) error {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.mu.Unlock()
+		return refusedError{ErrBusy}
+	}
+	backend := s.backend
+	if backend == nil {
+		s.mu.Unlock()
+		return refusedError{ErrBackendClosed}
+	}
+	if s.Status == StatusStopped {
+		s.mu.Unlock()
+		return refusedError{fmt.Errorf("%w: start it before sending", ErrStopped)}
+	}
+	if state, reason := s.Config.Budget.Check(s.Usage, s.Cost); state == BudgetHardExceeded {
+		s.mu.Unlock()
+		return refusedError{fmt.Errorf("%w: %s", ErrBudgetExceeded, reason)}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.LastPrompt = input
+	s.LastError = nil
+	admit := s.admit
+	policy := s.retry
+	s.mu.Unlock()
+
+	defer func() {
+		cancel()
+		s.mu.Lock()
+		s.cancel = nil
+		s.replying = false
+		s.mu.Unlock()
+	}()
+
+	if admit != nil {
+		if err := admit(); err != nil {
+			return refusedError{err}
+		}
+	}
+
+	if prompt != nil {
+		s.AddEntry(*prompt)
+	}
+	req := s.buildRequest(input, backend.Capabilities().ClientTools)
+	mark := len(s.GetTranscript())
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		s.SetStatus(StatusConnecting)
+		started := false
+		err = backend.Send(ctx, req, func(chunk Chunk) {
+			if !started {
+				started = true
+				s.SetStatus(StatusRunning)
+			}
+			s.handleChunk(chunk)
+			if onChunk != nil {
+				onChunk(chunk)
+			}
+		})
+		if err == nil || ctx.Err() != nil || s.halted() {
+			break
+		}
+
+		sendErr := classifyError(err)
+		sendErr.Attempts = attempt
+		err = sendErr
+		if !sendErr.Retryable || attempt >= policy.MaxAttempts {
+			break
+		}
+		// Drop the partial reply so the retried one does not repeat it.
+		s.truncate(mark)
+		if waitErr := s.waitRetry(ctx, sendErr, policy.Backoff(attempt, sendErr.RetryAfter)); waitErr != nil {
+			break
+		}
+		mark = len(s.GetTranscript())
+	}
+
+	if s.halted() {
+		// Stopped or killed mid-request, e.g. by a budget; keep that state.
+		s.mu.RLock()
+		reason := s.StopReason
+		s.mu.RUnlock()
+		if reason == "" {
+			return ErrStopped
+		}
+		return fmt.Errorf("%w: %s", ErrStopped, reason)
+	}
+
+	switch {
+	case err == nil:
+		s.SetStatus(StatusIdle)
+		s.emit(Event{Type: EventTurnCompleted, SessionID: s.ID})
+	case ctx.Err() == context.Canceled:
+		s.AddOutput("Request cancelled")
+		s.SetStatus(StatusIdle)
+		err = context.Canceled
+	default:
+		sendErr, ok := err.(*SendError)
+		if !ok {
+			sendErr = classifyError(err)
+		}
+		s.mu.Lock()
+		s.LastError = sendErr
+		s.mu.Unlock()
+		s.AddOutput("Error: " + sendErr.Error())
+		s.SetStatus(StatusError)
+		err = sendErr
+	}
+	return err
 }
 
-func generateID() string {
-	return time.Now().Format("20060102150405")
+func (s *Session) halted() bool {
+	status := s.GetStatus()
+	return status == StatusStopped || status == StatusKilled
 }
 
-type Manager struct {
-	sessions []*Session
-	mu       sync.RWMutex
+// truncate drops transcript entries from index n on.
+func (s *Session) truncate(n int) {
+	s.mu.Lock()
+	if n < len(s.Transcript) {
+		s.Transcript = s.Transcript[:n]
+		s.journal.write(journalRecord{Type: recordTruncate, Index: intPtr(n)})
+	}
+	s.replying = false
+	s.touch()
+	s.mu.Unlock()
+
+	s.emit(Event{Type: EventOutputAppended, SessionID: s.ID})
 }
 
-func NewManager() *Manager {
-	return &Manager{
-		sessions: make([]*Session, 0),
+// buildRequest assembles the next turn. Attached scratchpads are put in
+// front of the prompt sent, though not of the one in the transcript.
+func (s *Session) buildRequest(input string, clientTools bool) Request {
+	s.mu.RLock()
+	shared := s.shared
+	req := Request{
+		Input:       input,
+		Messages:    buildMessages(s.Transcript),
+		System:      s.Config.SystemPrompt,
+		Model:       s.Config.Model,
+		MaxTokens:   s.Config.MaxTokens,
+		Temperature: s.Config.Temperature,
 	}
+	s.mu.RUnlock()
+
+	if shared == nil {
+		return req
+	}
+	preamble, tools := shared(s, clientTools)
+	req.Tools = tools
+	if preamble != "" {
+		req.Input = preamble + input
+		if last := len(req.Messages) - 1; last >= 0 && req.Messages[last].Role == RoleUser {
+			req.Messages[last].Content = preamble + req.Messages[last].Content
+		}
+	}
+	return req
 }
 
-func (m *Manager) CreateSession(name string) *Session {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+func (s *Session) GetConfig() SessionConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Config
+}
 
-	session := NewSession(name)
-	m.sessions = append(m.sessions, session)
-	return session
+// SetConfig changes the settings used from the next request on.
+func (s *Session) SetConfig(config SessionConfig) {
+	s.mu.Lock()
+	s.Config = config
+	s.UpdatedAt = time.Now()
+	s.journalMeta()
+	s.mu.Unlock()
 }
 
-func (m *Manager) GetSessions() []*Session {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+// Cancel aborts the in-flight request, if any.
+func (s *Session) Cancel() {
+	s.mu.RLock()
+	cancel := s.cancel
+	s.mu.RUnlock()
 
-	sessions := make([]*Session, len(m.sessions))
-	copy(sessions, m.sessions)
-	return sessions
+	if cancel != nil {
+		cancel()
+	}
 }
 
-func (m *Manager) GetSession(id string) *Session {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+// Busy reports whether a request is in flight.
+func (s *Session) Busy() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cancel != nil
+}
 
-	for _, session := range m.sessions {
-		if session.ID == id {
-			return session
+func (s *Session) handleChunk(chunk Chunk) {
+	switch chunk.Type {
+	case ChunkText:
+		s.appendReply(ContentBlock{Type: BlockText, Text: chunk.Text})
+	case ChunkToolUse:
+		s.appendReply(ContentBlock{Type: BlockToolUse, Text: chunk.Text, ToolID: chunk.ToolID, ToolName: chunk.ToolName})
+	case ChunkToolResult:
+		s.AddEntry(Entry{
+			Role: RoleTool,
+			Blocks: []ContentBlock{{
+				Type:    BlockToolResult,
+				Text:    chunk.Text,
+				ToolID:  chunk.ToolID,
+				IsError: chunk.IsError,
+			}},
+		})
+	case ChunkUsage:
+		s.recordUsage(chunk.Usage)
+	case ChunkSessionID:
+		// A resumed Claude Code conversation may carry on under a new ID;
+		// resume that one next time.
+		s.mu.Lock()
+		if s.Config.ResumeID != "" && s.Config.ResumeID != chunk.Text {
+			s.Config.ResumeID = chunk.Text
+			s.journalMeta()
 		}
+		s.mu.Unlock()
 	}
-	return nil
 }
 
-func (m *Manager) RemoveSession(id string) bool {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// Close releases the session's backend.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	backend := s.backend
+	s.backend = nil
+	s.mu.Unlock()
 
-	for i, session := range m.sessions {
-		if session.ID == id {
-			m.sessions = append(m.sessions[:i], m.sessions[i+1:]...)
-			return true
-		}
+	if backend == nil {
+		return nil
+	}
+	return backend.Close()
+}
+
+func (s *Session) emit(event Event) {
+	s.mu.RLock()
+	notify := s.notify
+	s.mu.RUnlock()
+
+	if notify != nil {
+		notify(event)
 	}
-	return false
 }
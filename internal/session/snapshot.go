@@ -0,0 +1,192 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"claude-session-manager/internal/config"
+)
+
+// SnapshotVersion is bumped whenever the snapshot format changes in a way
+// older readers cannot handle.
+const SnapshotVersion = 1
+
+// Snapshot is the on-disk form of every session in a Manager, along with
+// the scratchpads they share.
+type Snapshot struct {
+	Version     int            `json:"version"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Sessions    []SessionState `json:"sessions"`
+	Scratchpads []Scratchpad   `json:"scratchpads,omitempty"`
+}
+
+// SessionState is everything needed to rebuild a session, minus its backend.
+type SessionState struct {
+	ID         string        `json:"id"`
+	Name       string        `json:"name"`
+	Alias      string        `json:"alias,omitempty"`
+	Status     Status        `json:"status"`
+	StopReason string        `json:"stop_reason,omitempty"`
+	Config     SessionConfig `json:"config"`
+	Transcript []Entry       `json:"transcript"`
+	Usage      Usage         `json:"usage"`
+	Cost       float64       `json:"cost"`
+	LastPrompt string        `json:"last_prompt,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Status) UnmarshalText(text []byte) error {
+	status, err := ParseStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
+}
+
+func ParseStatus(name string) (Status, error) {
+	for status := StatusIdle; status <= StatusKilled; status++ {
+		if status.String() == name {
+			return status, nil
+		}
+	}
+	return StatusIdle, fmt.Errorf("unknown status %q", name)
+}
+
+// State captures the session's current state.
+func (s *Session) State() SessionState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	transcript := make([]Entry, len(s.Transcript))
+	copy(transcript, s.Transcript)
+	return SessionState{
+		ID:         s.ID,
+		Name:       s.Name,
+		Alias:      s.Alias,
+		Status:     s.Status,
+		StopReason: s.StopReason,
+		Config:     s.Config,
+		Transcript: transcript,
+		Usage:      s.Usage,
+		Cost:       s.Cost,
+		LastPrompt: s.LastPrompt,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+// sessionFromState rebuilds a session in the stopped state; it has to be
+// started again before it accepts prompts.
+func sessionFromState(state SessionState, reason string) *Session {
+	s := NewSession(state.Name, state.Config)
+	s.ID = state.ID
+	s.Alias = state.Alias
+	s.Transcript = append(s.Transcript, state.Transcript...)
+	s.Usage = state.Usage
+	s.Cost = state.Cost
+	s.LastPrompt = state.LastPrompt
+	s.CreatedAt = state.CreatedAt
+	s.UpdatedAt = state.UpdatedAt
+	s.Status = StatusStopped
+	s.StopReason = reason
+	s.touch()
+	return s
+}
+
+func (m *Manager) Snapshot() Snapshot {
+	snapshot := Snapshot{Version: SnapshotVersion, CreatedAt: time.Now()}
+	for _, session := range m.GetSessions() {
+		snapshot.Sessions = append(snapshot.Sessions, session.State())
+	}
+	snapshot.Scratchpads = m.Scratchpads()
+	return snapshot
+}
+
+// SaveSnapshot writes all sessions to path.
+func (m *Manager) SaveSnapshot(path string) error {
+	return WriteSnapshot(path, m.Snapshot())
+}
+
+// WriteSnapshot writes snapshot to path. The file is replaced atomically so
+// a crash never leaves a half-written snapshot behind.
+func WriteSnapshot(path string, snapshot Snapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return config.WriteFileAtomic(path, data)
+}
+
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("parsing snapshot %s: %w", path, err)
+	}
+	if snapshot.Version < 1 || snapshot.Version > SnapshotVersion {
+		return nil, fmt.Errorf("snapshot %s has unsupported version %d", path, snapshot.Version)
+	}
+	return &snapshot, nil
+}
+
+// Restore adds the snapshot's sessions in the stopped state. Sessions whose
+// ID is already present are skipped, as are scratchpads whose name is. It
+// returns the sessions it added.
+func (m *Manager) Restore(snapshot *Snapshot) []*Session {
+	m.mu.Lock()
+	for _, pad := range snapshot.Scratchpads {
+		if _, ok := m.scratchpads[pad.Name]; !ok && validScratchpadName(pad.Name) == nil {
+			m.scratchpads[pad.Name] = &pad
+		}
+	}
+	m.mu.Unlock()
+
+	var restored []*Session
+	for _, state := range snapshot.Sessions {
+		if session := m.restoreState(state, "restored from snapshot", nil); session != nil {
+			restored = append(restored, session)
+		}
+	}
+	return restored
+}
+
+// Reopen adds an archived session back in the stopped state. If the session
+// is already open, that session is returned.
+func (m *Manager) Reopen(state SessionState) *Session {
+	if session := m.GetSession(state.ID); session != nil {
+		return session
+	}
+	return m.restoreState(state, "reopened from the archive", nil)
+}
+
+// restoreState adds a session rebuilt from state unless its ID is taken,
+// dropping its alias if another session already uses it. A non-nil journal
+// is kept instead of starting a new one.
+func (m *Manager) restoreState(state SessionState, reason string, journal *Journal) *Session {
+	if m.GetSession(state.ID) != nil {
+		return nil
+	}
+	if state.Alias != "" {
+		if existing, err := m.Resolve("@" + state.Alias); err == nil && existing != nil {
+			state.Alias = ""
+		}
+	}
+	session := sessionFromState(state, reason)
+	if journal != nil {
+		session.journal = journal
+		journal.write(journalRecord{Type: recordStatus, Status: &session.Status, Reason: session.StopReason})
+	}
+	m.add(session)
+	return session
+}
@@ -0,0 +1,105 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	m := NewManager()
+	idle := m.CreateSession("idle", m.DefaultConfig())
+	if err := idle.SendInput("hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetAlias(idle.ID, "greeter"); err != nil {
+		t.Fatal(err)
+	}
+	stopped := m.CreateSession("stopped", m.DefaultConfig())
+	if err := stopped.Stop("paused"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CreateScratchpad("notes"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.WriteScratchpad("notes", "remember this", idle); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := m.SaveSnapshot(path); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Version != SnapshotVersion || len(snapshot.Sessions) != 2 {
+		t.Fatalf("loaded version %d with %d sessions", snapshot.Version, len(snapshot.Sessions))
+	}
+
+	restoring := NewManager()
+	restored := restoring.Restore(snapshot)
+	if len(restored) != 2 {
+		t.Fatalf("restored %d sessions, want 2", len(restored))
+	}
+	for i, original := range []*Session{idle, stopped} {
+		sess := restored[i]
+		if sess.ID != original.ID || sess.Name != original.Name || sess.Alias != original.Alias {
+			t.Errorf("restored %s %q @%s, want %s %q @%s", sess.ID, sess.Name, sess.Alias, original.ID, original.Name, original.Alias)
+		}
+		if status := sess.GetStatus(); status != StatusStopped {
+			t.Errorf("%s restored %s, want stopped", sess.Name, status)
+		}
+		if reason := sess.GetStopReason(); reason != "restored from snapshot" {
+			t.Errorf("%s stop reason = %q", sess.Name, reason)
+		}
+		sameEntries(t, sess.GetTranscript(), original.GetTranscript())
+		usage, cost := sess.GetUsage()
+		wantUsage, wantCost := original.GetUsage()
+		if usage != wantUsage || cost != wantCost {
+			t.Errorf("%s usage = %+v $%g, want %+v $%g", sess.Name, usage, cost, wantUsage, wantCost)
+		}
+	}
+	if err := restored[0].SendInput("again"); err == nil {
+		t.Error("a restored session accepted a prompt before being started")
+	}
+	pad, ok := restoring.GetScratchpad("notes")
+	if !ok || pad.Current().Content != "remember this" || pad.Current().Author != idle.ID {
+		t.Errorf("restored scratchpad = %+v", pad)
+	}
+
+	// Restoring again adds nothing: the IDs are taken.
+	if again := restoring.Restore(snapshot); len(again) != 0 {
+		t.Errorf("restored %d sessions twice", len(again))
+	}
+}
+
+func TestLoadSnapshotVersion(t *testing.T) {
+	dir := t.TempDir()
+	for _, tt := range []struct {
+		data    string
+		wantErr string
+	}{
+		{data: `{"version": 0, "sessions": []}`, wantErr: "unsupported version 0"},
+		{data: `{"version": 2, "sessions": []}`, wantErr: "unsupported version 2"},
+		{data: `{"version": 1, "sessions": [`, wantErr: "parsing snapshot"},
+		{data: `{"version": 1, "sessions": []}`},
+	} {
+		path := filepath.Join(dir, "snapshot.json")
+		if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadSnapshot(path)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("LoadSnapshot(%s) = %v", tt.data, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("LoadSnapshot(%s) = %v, want an error containing %q", tt.data, err, tt.wantErr)
+		}
+	}
+}
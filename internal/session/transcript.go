@@ -0,0 +1,112 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+	RoleSystem    Role = "system"
+	RoleTool      Role = "tool"
+)
+
+type BlockType string
+
+const (
+	BlockText       BlockType = "text"
+	BlockToolUse    BlockType = "tool_use"
+	BlockToolResult BlockType = "tool_result"
+)
+
+// ContentBlock is one part of a transcript entry. For tool blocks Text holds
+// the tool input (BlockToolUse) or output (BlockToolResult).
+type ContentBlock struct {
+	Type     BlockType `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ToolID   string    `json:"tool_id,omitempty"`
+	ToolName string    `json:"tool_name,omitempty"`
+	IsError  bool      `json:"is_error,omitempty"`
+}
+
+// Entry is a single message in a session's transcript. SessionID is the
+// session the content came from, which is not always the one holding it;
+// Source is that session's display name when the entry was piped in.
+type Entry struct {
+	Role      Role           `json:"role"`
+	Blocks    []ContentBlock `json:"blocks"`
+	Timestamp time.Time      `json:"timestamp"`
+	Usage     *Usage         `json:"usage,omitempty"`
+	SessionID string         `json:"session_id,omitempty"`
+	Source    string         `json:"source,omitempty"`
+}
+
+func TextEntry(role Role, text string) Entry {
+	return Entry{
+		Role:      role,
+		Blocks:    []ContentBlock{{Type: BlockText, Text: text}},
+		Timestamp: time.Now(),
+	}
+}
+
+// Text returns the entry's text blocks joined together, ignoring tool blocks.
+func (e Entry) Text() string {
+	var parts []string
+	for _, block := range e.Blocks {
+		if block.Type == BlockText {
+			parts = append(parts, block.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Lines renders the entry the way the output pane shows it.
+func (e Entry) Lines() []string {
+	var lines []string
+	if e.Source != "" {
+		lines = append(lines, "⇐ piped from "+e.Source)
+	}
+	start := len(lines)
+	for _, block := range e.Blocks {
+		switch block.Type {
+		case BlockText:
+			for i, line := range strings.Split(block.Text, "\n") {
+				switch {
+				case e.Role != RoleUser:
+					lines = append(lines, line)
+				case i == 0 && len(lines) == start:
+					lines = append(lines, "> "+line)
+				default:
+					lines = append(lines, "  "+line)
+				}
+			}
+		case BlockToolUse:
+			lines = append(lines, fmt.Sprintf("🔧 %s %s", block.ToolName, block.Text))
+		case BlockToolResult:
+			prefix := "↳"
+			if block.IsError {
+				prefix = "↳ ✗"
+			}
+			for _, line := range strings.Split(strings.TrimRight(block.Text, "\n"), "\n") {
+				lines = append(lines, fmt.Sprintf("%s %s", prefix, line))
+			}
+		}
+	}
+	if e.Usage != nil {
+		lines = append(lines, fmt.Sprintf("✓ Done (%d in / %d out tokens)", e.Usage.InputTokens, e.Usage.OutputTokens))
+	}
+	return lines
+}
+
+// RenderLines flattens a transcript into output pane lines.
+func RenderLines(entries []Entry) []string {
+	var lines []string
+	for _, entry := range entries {
+		lines = append(lines, entry.Lines()...)
+	}
+	return lines
+}
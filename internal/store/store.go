@@ -0,0 +1,388 @@
+// Package store keeps session transcripts on disk and indexes them for
+// full-text search.
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"claude-session-manager/internal/config"
+	"claude-session-manager/internal/session"
+)
+
+// Store is a directory of archived sessions, one JSON file each, plus an
+// in-memory inverted index over the output lines of every session it knows.
+// Sessions that are still open can be indexed without being written out.
+type Store struct {
+	dir string
+
+	mu   sync.RWMutex
+	docs map[string]*document
+	// index maps a term to the sessions containing it and, for each, the
+	// output lines it appears on in ascending order.
+	index map[string]map[string][]int
+	// skipped holds why archive files Open could not load were left out.
+	skipped []error
+}
+
+type document struct {
+	state session.SessionState
+	lines []string
+	terms []string
+}
+
+// Hit is one output line that matched a search.
+type Hit struct {
+	SessionID string
+	Name      string
+	Alias     string
+	// Line indexes the session's output as rendered by session.RenderLines.
+	Line      int
+	Text      string
+	UpdatedAt time.Time
+}
+
+// Open loads and indexes every session archived in dir, creating dir if it
+// does not exist. Files that cannot be read or parsed are left out rather
+// than making the whole archive unavailable; Skipped says which.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	s := &Store{
+		dir:   dir,
+		docs:  make(map[string]*document),
+		index: make(map[string]map[string][]int),
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			s.skipped = append(s.skipped, err)
+			continue
+		}
+		var state session.SessionState
+		if err := json.Unmarshal(data, &state); err != nil {
+			s.skipped = append(s.skipped, fmt.Errorf("parsing %s: %w", path, err))
+			continue
+		}
+		s.Index(state)
+	}
+	return s, nil
+}
+
+// Skipped returns an error for each archive file Open left out.
+func (s *Store) Skipped() []error {
+	return s.skipped
+}
+
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Archive writes the session to the store and indexes it. It implements
+// session.Archiver.
+func (s *Store) Archive(state session.SessionState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := config.WriteFileAtomic(filepath.Join(s.dir, state.ID+".json"), data); err != nil {
+		return err
+	}
+	s.Index(state)
+	return nil
+}
+
+// Index makes the session searchable without writing it to disk, replacing
+// whatever was indexed for it before. Older states than the one indexed are
+// ignored.
+func (s *Store) Index(state session.SessionState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if old, ok := s.docs[state.ID]; ok {
+		if state.UpdatedAt.Before(old.state.UpdatedAt) {
+			return
+		}
+		if state.UpdatedAt.Equal(old.state.UpdatedAt) && len(state.Transcript) == len(old.state.Transcript) {
+			old.state = state
+			return
+		}
+		s.unindex(old)
+	}
+
+	doc := &document{state: state, lines: session.RenderLines(state.Transcript)}
+	seen := make(map[string]bool)
+	for i, line := range doc.lines {
+		for _, term := range tokenize(line) {
+			postings := s.index[term]
+			if postings == nil {
+				postings = make(map[string][]int)
+				s.index[term] = postings
+			}
+			if lines := postings[state.ID]; len(lines) == 0 || lines[len(lines)-1] != i {
+				postings[state.ID] = append(lines, i)
+			}
+			if !seen[term] {
+				seen[term] = true
+				doc.terms = append(doc.terms, term)
+			}
+		}
+	}
+	s.docs[state.ID] = doc
+}
+
+// unindex drops doc's postings. Callers must hold s.mu.
+func (s *Store) unindex(doc *document) {
+	for _, term := range doc.terms {
+		delete(s.index[term], doc.state.ID)
+		if len(s.index[term]) == 0 {
+			delete(s.index, term)
+		}
+	}
+	delete(s.docs, doc.state.ID)
+}
+
+// IndexJournals indexes the sessions recorded in the journals in dir, so
+// sessions open in another process can be searched too.
+func (s *Store) IndexJournals(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		state, _, err := session.ReadJournal(path)
+		if err != nil {
+			continue
+		}
+		s.Index(state)
+	}
+	return nil
+}
+
+// Get returns the latest known state of a session.
+func (s *Store) Get(id string) (session.SessionState, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	doc, ok := s.docs[id]
+	if !ok {
+		return session.SessionState{}, false
+	}
+	return doc.state, true
+}
+
+// States returns the latest known state of every session, oldest first.
+func (s *Store) States() []session.SessionState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	states := make([]session.SessionState, 0, len(s.docs))
+	for _, doc := range s.docs {
+		states = append(states, doc.state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].CreatedAt.Before(states[j].CreatedAt) })
+	return states
+}
+
+// Resolve finds a session by ID, @alias or unambiguous ID prefix, the same
+// references session.Manager.Resolve accepts. Aliases are reused over time,
+// so an alias resolves to the most recently updated session holding it.
+func (s *Store) Resolve(ref string) (session.SessionState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if doc, ok := s.docs[ref]; ok {
+		return doc.state, nil
+	}
+
+	var byAlias, byPrefix *document
+	alias := strings.TrimPrefix(ref, "@")
+	for id, doc := range s.docs {
+		if alias != "" && doc.state.Alias == alias {
+			if byAlias == nil || doc.state.UpdatedAt.After(byAlias.state.UpdatedAt) {
+				byAlias = doc
+			}
+		}
+		if ref != "" && strings.HasPrefix(id, ref) {
+			if byPrefix != nil {
+				return session.SessionState{}, fmt.Errorf("session reference %q is ambiguous", ref)
+			}
+			byPrefix = doc
+		}
+	}
+	switch {
+	case byAlias != nil:
+		return byAlias.state, nil
+	case byPrefix != nil:
+		return byPrefix.state, nil
+	}
+	return session.SessionState{}, fmt.Errorf("%w: %s", session.ErrSessionNotFound, ref)
+}
+
+// Search finds the output lines containing query, ignoring case. Hits come
+// from the most recently updated sessions first and in line order within a
+// session. A limit of zero or less returns every hit.
+func (s *Store) Search(query string, limit int) []Hit {
+	phrase := strings.ToLower(strings.TrimSpace(query))
+	if phrase == "" {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	candidates := s.candidates(tokenize(phrase))
+	docs := make([]*document, 0, len(candidates))
+	for id := range candidates {
+		docs = append(docs, s.docs[id])
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].state.UpdatedAt.After(docs[j].state.UpdatedAt)
+	})
+
+	var hits []Hit
+	for _, doc := range docs {
+		for _, i := range candidates[doc.state.ID] {
+			// The index narrows the search to lines with every term; the
+			// phrase itself still has to be checked.
+			if !strings.Contains(strings.ToLower(doc.lines[i]), phrase) {
+				continue
+			}
+			hits = append(hits, Hit{
+				SessionID: doc.state.ID,
+				Name:      doc.state.Name,
+				Alias:     doc.state.Alias,
+				Line:      i,
+				Text:      doc.lines[i],
+				UpdatedAt: doc.state.UpdatedAt,
+			})
+			if limit > 0 && len(hits) == limit {
+				return hits
+			}
+		}
+	}
+	return hits
+}
+
+// candidates returns, per session, the lines that contain every term. With
+// no terms, such as for a query of only punctuation, every line is a
+// candidate. Callers must hold s.mu.
+func (s *Store) candidates(terms []string) map[string][]int {
+	result := make(map[string][]int)
+	if len(terms) == 0 {
+		for id, doc := range s.docs {
+			lines := make([]int, len(doc.lines))
+			for i := range lines {
+				lines[i] = i
+			}
+			result[id] = lines
+		}
+		return result
+	}
+
+	// The terms at either end of the query may be cut short, as in
+	// "llo wor" for "hello world", so they only have to end or start the
+	// indexed term respectively.
+	for i, term := range terms {
+		var match func(indexed string) bool
+		switch {
+		case len(terms) == 1:
+			match = func(indexed string) bool { return strings.Contains(indexed, term) }
+		case i == 0:
+			match = func(indexed string) bool { return strings.HasSuffix(indexed, term) }
+		case i == len(terms)-1:
+			match = func(indexed string) bool { return strings.HasPrefix(indexed, term) }
+		}
+		postings := s.postings(term, match)
+		if i == 0 {
+			for id, lines := range postings {
+				result[id] = lines
+			}
+			continue
+		}
+		for id, lines := range result {
+			if common := intersect(lines, postings[id]); len(common) > 0 {
+				result[id] = common
+			} else {
+				delete(result, id)
+			}
+		}
+	}
+	return result
+}
+
+// postings returns the lines containing term or, when match is set, any
+// indexed term it accepts. Callers must hold s.mu.
+func (s *Store) postings(term string, match func(indexed string) bool) map[string][]int {
+	if match == nil {
+		return s.index[term]
+	}
+	merged := make(map[string][]int)
+	for indexed, postings := range s.index {
+		if !match(indexed) {
+			continue
+		}
+		for id, lines := range postings {
+			merged[id] = union(merged[id], lines)
+		}
+	}
+	return merged
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// intersect and union combine sorted line lists.
+func intersect(a, b []int) []int {
+	var out []int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+func union(a, b []int) []int {
+	out := make([]int, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			out = append(out, a[i])
+			i++
+		case a[i] > b[j]:
+			out = append(out, b[j])
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	out = append(out, a[i:]...)
+	return append(out, b[j:]...)
+}
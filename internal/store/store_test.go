@@ -0,0 +1,143 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"claude-session-manager/internal/session"
+)
+
+var epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// state builds a session whose replies are lines, updated minutes after
+// epoch.
+func state(id string, minutes int, lines ...string) session.SessionState {
+	transcript := make([]session.Entry, len(lines))
+	for i, line := range lines {
+		transcript[i] = session.TextEntry(session.RoleAssistant, line)
+	}
+	at := epoch.Add(time.Duration(minutes) * time.Minute)
+	return session.SessionState{ID: id, Name: "session " + id, Transcript: transcript, CreatedAt: at, UpdatedAt: at}
+}
+
+type hitKey struct {
+	id   string
+	line int
+}
+
+func keys(hits []Hit) []hitKey {
+	out := make([]hitKey, len(hits))
+	for i, hit := range hits {
+		out[i] = hitKey{hit.SessionID, hit.Line}
+	}
+	return out
+}
+
+func TestSearch(t *testing.T) {
+	s := &Store{docs: make(map[string]*document), index: make(map[string]map[string][]int)}
+	s.Index(state("old", 1,
+		"Hello world",
+		"world, hello",
+		"say hello to the WORLD again",
+	))
+	s.Index(state("new", 2,
+		"Othello worldwide tour",
+		"nothing here",
+		"hello",
+	))
+
+	tests := []struct {
+		query string
+		limit int
+		want  []hitKey
+	}{
+		// Newer sessions come first, then lines in order.
+		{query: "hello", want: []hitKey{{"new", 0}, {"new", 2}, {"old", 0}, {"old", 1}, {"old", 2}}},
+		{query: "hello", limit: 3, want: []hitKey{{"new", 0}, {"new", 2}, {"old", 0}}},
+		// A single term may match inside an indexed term.
+		{query: "ELL", want: []hitKey{{"new", 0}, {"new", 2}, {"old", 0}, {"old", 1}, {"old", 2}}},
+		// Every term must be on the line, in order and next to each other,
+		// though the phrase may start and end inside words.
+		{query: "hello world", want: []hitKey{{"new", 0}, {"old", 0}}},
+		{query: "the world", want: []hitKey{{"old", 2}}},
+		// The ends of a phrase may be cut short.
+		{query: "llo wor", want: []hitKey{{"new", 0}, {"old", 0}}},
+		{query: "world, hel", want: []hitKey{{"old", 1}}},
+		// Only punctuation still has to appear verbatim.
+		{query: ",", want: []hitKey{{"old", 1}}},
+		{query: "missing", want: nil},
+		{query: "   ", want: nil},
+	}
+	for _, tt := range tests {
+		got := keys(s.Search(tt.query, tt.limit))
+		if len(got) != len(tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+				break
+			}
+		}
+	}
+
+	hit := s.Search("tour", 0)[0]
+	if hit.Text != "Othello worldwide tour" || hit.Name != "session new" || !hit.UpdatedAt.Equal(epoch.Add(2*time.Minute)) {
+		t.Errorf("hit = %+v", hit)
+	}
+}
+
+func TestIndexReplaces(t *testing.T) {
+	s := &Store{docs: make(map[string]*document), index: make(map[string]map[string][]int)}
+	s.Index(state("a", 1, "first draft"))
+	s.Index(state("a", 2, "second version"))
+
+	if hits := s.Search("draft", 0); len(hits) != 0 {
+		t.Errorf("the replaced transcript is still found: %+v", hits)
+	}
+	if _, ok := s.index["draft"]; ok {
+		t.Error("the replaced transcript's terms are still indexed")
+	}
+	if hits := s.Search("second", 0); len(hits) != 1 {
+		t.Errorf("Search(second) = %+v, want one hit", hits)
+	}
+
+	// An older state than the one indexed is ignored.
+	s.Index(state("a", 0, "stale copy"))
+	if hits := s.Search("stale", 0); len(hits) != 0 {
+		t.Errorf("an older state replaced a newer one: %+v", hits)
+	}
+}
+
+func TestOpenSkipsBadFiles(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Archive(state("good", 1, "kept reply")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{"id": "bad", "transcript": [`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Open(dir)
+	if err != nil {
+		t.Fatalf("one bad file made the store unavailable: %v", err)
+	}
+	skipped := s.Skipped()
+	if len(skipped) != 1 || !strings.Contains(skipped[0].Error(), "bad.json") {
+		t.Errorf("Skipped() = %v, want bad.json", skipped)
+	}
+	if _, ok := s.Get("good"); !ok {
+		t.Error("the good session was not loaded")
+	}
+	if hits := s.Search("kept", 0); len(hits) != 1 {
+		t.Errorf("Search(kept) = %+v, want one hit", hits)
+	}
+}
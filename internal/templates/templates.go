@@ -0,0 +1,260 @@
+// Package templates is a library of reusable prompts. Each template is a
+// text/template file whose front matter declares the variables it takes:
+//
+//	---
+//	description: Review a change
+//	variables:
+//	  - name: language
+//	    description: Language of the code
+//	    default: Go
+//	  - name: code
+//	    description: The code to review
+//	---
+//	Review this {{.language}} code:
+//
+//	{{.code}}
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ext is the extension template files have.
+const Ext = ".tmpl"
+
+var ErrNotFound = errors.New("template not found")
+
+// Variable is a value a template asks for. Variables without a default are
+// required.
+type Variable struct {
+	Name        string
+	Description string
+	Default     string
+	Required    bool
+}
+
+// Template is a parsed prompt template, named after its file.
+type Template struct {
+	Name        string
+	Path        string
+	Description string
+	Variables   []Variable
+	Body        string
+
+	tmpl *template.Template
+}
+
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Load reads and parses a template file.
+func Load(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t, err := Parse(strings.TrimSuffix(filepath.Base(path), Ext), string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	t.Path = path
+	return t, nil
+}
+
+// LoadDir loads every template in dir, sorted by name. A missing directory
+// holds no templates. Files that fail to load are reported together in the
+// error; the others are still returned.
+func LoadDir(dir string) ([]*Template, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+Ext))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var (
+		list []*Template
+		errs []error
+	)
+	for _, path := range paths {
+		t, err := Load(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		list = append(list, t)
+	}
+	return list, errors.Join(errs...)
+}
+
+// Find loads the template called name from dir.
+func Find(dir, name string) (*Template, error) {
+	if strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	path := filepath.Join(dir, name+Ext)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s (looked in %s)", ErrNotFound, name, dir)
+	}
+	return Load(path)
+}
+
+// Parse reads a template: optional front matter between "---" lines, then
+// the body.
+func Parse(name, data string) (*Template, error) {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	t := &Template{Name: name, Body: data}
+
+	if rest, ok := strings.CutPrefix(data, "---\n"); ok {
+		front, body, found := strings.Cut(rest, "\n---\n")
+		if !found {
+			front, found = strings.CutSuffix(rest, "\n---")
+		}
+		if !found {
+			return nil, errors.New("front matter has no closing ---")
+		}
+		if err := t.decodeFrontMatter(front); err != nil {
+			return nil, err
+		}
+		t.Body = body
+	}
+
+	var err error
+	t.tmpl, err = template.New(name).Option("missingkey=error").Parse(t.Body)
+	if err != nil {
+		return nil, err
+	}
+	// Render once with every variable empty to catch references to ones the
+	// front matter does not declare.
+	if err := t.tmpl.Execute(new(strings.Builder), t.values(nil, true)); err != nil {
+		return nil, fmt.Errorf("%w (declare every variable the template uses)", err)
+	}
+	return t, nil
+}
+
+// frontMatter is the layout of a template's front matter.
+type frontMatter struct {
+	Description string         `yaml:"description"`
+	Variables   []variableSpec `yaml:"variables"`
+}
+
+// variableSpec declares a variable either by name alone or as a mapping
+// with a description and a default.
+type variableSpec Variable
+
+func (v *variableSpec) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		v.Required = true
+		return node.Decode(&v.Name)
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: want a name or a mapping", node.Line)
+	}
+	// Node.Decode ignores unknown keys, so check them here.
+	hasDefault := false
+	for i := 0; i < len(node.Content); i += 2 {
+		key := node.Content[i]
+		switch key.Value {
+		case "name", "description":
+		case "default":
+			hasDefault = true
+		default:
+			return fmt.Errorf("line %d: field %s not found", key.Line, key.Value)
+		}
+	}
+	var spec struct {
+		Name        string `yaml:"name"`
+		Description string `yaml:"description"`
+		Default     string `yaml:"default"`
+	}
+	if err := node.Decode(&spec); err != nil {
+		return err
+	}
+	*v = variableSpec{Name: spec.Name, Description: spec.Description, Default: spec.Default, Required: !hasDefault}
+	return nil
+}
+
+func (t *Template) decodeFrontMatter(front string) error {
+	var doc frontMatter
+	decoder := yaml.NewDecoder(strings.NewReader(front))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("front matter: %w", err)
+	}
+	t.Description = doc.Description
+
+	seen := make(map[string]bool)
+	for i, spec := range doc.Variables {
+		path := fmt.Sprintf("variables[%d]", i)
+		v := Variable(spec)
+		if !variableName.MatchString(v.Name) {
+			return fmt.Errorf("%s: variable name %q must be letters, digits and '_'", path, v.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("%s: variable %q is declared twice", path, v.Name)
+		}
+		seen[v.Name] = true
+		t.Variables = append(t.Variables, v)
+	}
+	return nil
+}
+
+// Variable returns the declared variable called name.
+func (t *Template) Variable(name string) (Variable, bool) {
+	for _, v := range t.Variables {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Variable{}, false
+}
+
+// Render fills the template in from values, using defaults for the
+// variables values leaves out.
+func (t *Template) Render(values map[string]string) (string, error) {
+	for name := range values {
+		if _, ok := t.Variable(name); !ok {
+			return "", fmt.Errorf("%s has no variable %q", t.Name, name)
+		}
+	}
+	var missing []string
+	for _, v := range t.Variables {
+		if _, ok := values[v.Name]; !ok && v.Required {
+			missing = append(missing, v.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%s needs a value for %s", t.Name, strings.Join(missing, ", "))
+	}
+
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, t.values(values, false)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// values completes values with the defaults, or with empty strings when
+// blank is set.
+func (t *Template) values(values map[string]string, blank bool) map[string]string {
+	data := make(map[string]string, len(t.Variables))
+	for _, v := range t.Variables {
+		switch value, ok := values[v.Name]; {
+		case ok:
+			data[v.Name] = value
+		case !blank:
+			data[v.Name] = v.Default
+		default:
+			data[v.Name] = ""
+		}
+	}
+	return data
+}
@@ -0,0 +1,67 @@
+package templates
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	tmpl, err := Parse("review", `---
+description: "Review ✓"
+variables:
+  - code
+  - {name: language, description: Language of the code, default: Go}
+  - name: note
+    default:
+---
+Review this {{.language}} code{{.note}}:
+
+{{.code}}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Description != "Review ✓" {
+		t.Errorf("description = %q", tmpl.Description)
+	}
+	want := []Variable{
+		{Name: "code", Required: true},
+		{Name: "language", Description: "Language of the code", Default: "Go"},
+		{Name: "note"},
+	}
+	if !reflect.DeepEqual(tmpl.Variables, want) {
+		t.Errorf("variables = %+v, want %+v", tmpl.Variables, want)
+	}
+
+	got, err := tmpl.Render(map[string]string{"code": "x := 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "Review this Go code:\n\nx := 1\n" {
+		t.Errorf("Render() = %q", got)
+	}
+}
+
+func TestParseFrontMatterErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		front   string
+		wantErr string
+	}{
+		{name: "unknown key", front: "descripton: typo", wantErr: "line 1: field descripton not found"},
+		{name: "unknown variable key", front: "variables:\n  - name: a\n    defualt: x", wantErr: "line 3: field defualt not found"},
+		{name: "variable list", front: "variables:\n  - [a, b]", wantErr: "line 2: want a name or a mapping"},
+		{name: "bad name", front: "variables: [a-b]", wantErr: `variables[0]: variable name "a-b" must be`},
+		{name: "declared twice", front: "variables: [a, a]", wantErr: `variables[1]: variable "a" is declared twice`},
+		{name: "bad syntax", front: "variables: [a", wantErr: "front matter: yaml:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse("t", "---\n"+tt.front+"\n---\nbody\n")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
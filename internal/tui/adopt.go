@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	"claude-session-manager/internal/session"
+)
+
+// importClaudeLogs adds every Claude Code conversation that is not open yet.
+func (m *Model) importClaudeLogs() {
+	dir, err := session.ClaudeProjectsDir()
+	if err != nil {
+		m.notice = "Import failed: " + err.Error()
+		return
+	}
+	imported, err := m.sessionManager.ImportClaudeLogs(dir)
+	switch {
+	case err != nil && len(imported) == 0:
+		m.notice = "Import failed: " + err.Error()
+	case len(imported) == 0:
+		m.notice = "No new Claude Code conversations in " + dir
+	default:
+		m.notice = fmt.Sprintf("Imported %d Claude Code conversations; A adopts one", len(imported))
+	}
+}
+
+// adoptSelected starts an imported conversation so the next prompt continues
+// it through the claude CLI.
+func (m *Model) adoptSelected() {
+	sess := m.selectedSession
+	if sess == nil {
+		return
+	}
+	config := sess.GetConfig()
+	if config.ResumeID == "" {
+		m.notice = "Only sessions imported from Claude Code can be adopted"
+		return
+	}
+	if sess.GetStatus() == session.StatusStopped {
+		if err := sess.Start(context.Background()); err != nil {
+			m.notice = "Adopt failed: " + err.Error()
+			return
+		}
+	}
+	m.focusedPane = InputPane
+	m.notice = "Prompts now continue Claude Code session " + config.ResumeID
+}
@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"fmt"
+
+	"claude-session-manager/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// delivery is how far a broadcast prompt got with one of its targets.
+type delivery int
+
+const (
+	deliverySending delivery = iota
+	deliveryDone
+	deliveryFailed
+	deliverySkipped
+)
+
+// toggleMark adds the selected session to the broadcast targets, or takes
+// it off them.
+func (m *Model) toggleMark() {
+	if m.selectedSession == nil {
+		return
+	}
+	if m.marked == nil {
+		m.marked = make(map[string]bool)
+	}
+	if m.marked[m.selectedSession.ID] {
+		delete(m.marked, m.selectedSession.ID)
+	} else {
+		m.marked[m.selectedSession.ID] = true
+	}
+}
+
+// markedSessions returns the broadcast targets in list order.
+func (m *Model) markedSessions() []*session.Session {
+	var marked []*session.Session
+	for _, sess := range m.sessionManager.GetSessions() {
+		if m.marked[sess.ID] {
+			marked = append(marked, sess)
+		}
+	}
+	return marked
+}
+
+// broadcast sends input to every marked session at once. Sessions that are
+// busy or stopped are skipped rather than holding up the rest.
+func (m *Model) broadcast(input string) tea.Cmd {
+	targets := m.markedSessions()
+	m.deliveries = make(map[string]delivery, len(targets))
+
+	var cmds []tea.Cmd
+	for _, sess := range targets {
+		if sess.Busy() || sess.GetStatus() == session.StatusStopped {
+			m.deliveries[sess.ID] = deliverySkipped
+			continue
+		}
+		m.deliveries[sess.ID] = deliverySending
+		cmds = append(cmds, sendInputCmd(sess, input))
+	}
+
+	m.notice = fmt.Sprintf("Broadcast to %d of %d marked sessions", len(cmds), len(targets))
+	return tea.Batch(cmds...)
+}
+
+// finishDelivery records how a broadcast request to a session ended.
+func (m *Model) finishDelivery(sessionID string, err error) {
+	if m.deliveries[sessionID] != deliverySending {
+		return
+	}
+	if err != nil {
+		m.deliveries[sessionID] = deliveryFailed
+	} else {
+		m.deliveries[sessionID] = deliveryDone
+	}
+}
+
+// deliveryIndicator shows how the last broadcast went for a session.
+func (m *Model) deliveryIndicator(sess *session.Session) string {
+	state, ok := m.deliveries[sess.ID]
+	if !ok {
+		return ""
+	}
+	switch state {
+	case deliverySending:
+		return m.styles.StatusRunning.Render("⇢ sending")
+	case deliveryDone:
+		return m.styles.StatusIdle.Render("✔ delivered")
+	case deliveryFailed:
+		return m.styles.StatusError.Render("✗ failed")
+	default:
+		return m.styles.StatusStopped.Render("– skipped")
+	}
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	"claude-session-manager/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// streamChunkMsg carries one chunk of a reply that is still streaming.
+type streamChunkMsg struct {
+	sessionID string
+	chunk     session.Chunk
+	stream    <-chan tea.Msg
+}
+
+// sendDoneMsg reports that a request finished, successfully or not.
+type sendDoneMsg struct {
+	sessionID string
+	err       error
+}
+
+// sendInputCmd starts a request in the background and returns a command that
+// delivers its chunks and final result as messages.
+func sendInputCmd(sess *session.Session, input string) tea.Cmd {
+	return streamCmd(sess, func(ctx context.Context, onChunk func(session.Chunk)) error {
+		return sess.Send(ctx, input, onChunk)
+	})
+}
+
+// pipeCmd sends a pipe's prompt to its target in the background.
+func pipeCmd(pipe session.Pipe) tea.Cmd {
+	return streamCmd(pipe.To, pipe.Send)
+}
+
+// retryLastCmd resends the session's last prompt in the background.
+func retryLastCmd(sess *session.Session) tea.Cmd {
+	return streamCmd(sess, sess.RetryLast)
+}
+
+func streamCmd(sess *session.Session, send func(context.Context, func(session.Chunk)) error) tea.Cmd {
+	stream := make(chan tea.Msg, 64)
+	go func() {
+		defer close(stream)
+		err := send(context.Background(), func(chunk session.Chunk) {
+			stream <- streamChunkMsg{sessionID: sess.ID, chunk: chunk, stream: stream}
+		})
+		stream <- sendDoneMsg{sessionID: sess.ID, err: err}
+	}()
+	return waitForStream(stream)
+}
+
+// tickMsg drives once-a-second redraws for countdowns.
+type tickMsg time.Time
+
+func tickCmd() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+func waitForStream(stream <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-stream
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"claude-session-manager/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sessionEventMsg wraps a session.Event so it can flow through Update.
+type sessionEventMsg struct {
+	event session.Event
+}
+
+// listenForEvents waits for the next session event. Update re-issues it after
+// every event so the subscription stays live for the program's lifetime.
+func listenForEvents(events <-chan session.Event) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return nil
+		}
+		return sessionEventMsg{event: event}
+	}
+}
+
+func (m *Model) handleSessionEvent(event session.Event) {
+	switch event.Type {
+	case session.EventSessionCreated, session.EventSessionRemoved:
+		if event.Type == session.EventSessionRemoved {
+			delete(m.marked, event.SessionID)
+			delete(m.deliveries, event.SessionID)
+		}
+		m.syncSelection()
+
+	case session.EventOutputAppended:
+		if m.followOutput && m.selectedSession != nil && m.selectedSession.ID == event.SessionID {
+			m.scrollOutputToBottom()
+		}
+	}
+}
+
+// syncSelection keeps the cursor and selected session valid after the
+// session list changed underneath the model.
+func (m *Model) syncSelection() {
+	sessions := m.sessionManager.GetSessions()
+	if len(sessions) == 0 {
+		m.selectedSession = nil
+		m.sessionCursor = 0
+		return
+	}
+
+	if m.selectedSession != nil {
+		for i, sess := range sessions {
+			if sess.ID == m.selectedSession.ID {
+				m.sessionCursor = i
+				return
+			}
+		}
+	}
+
+	if m.sessionCursor >= len(sessions) {
+		m.sessionCursor = len(sessions) - 1
+	}
+	m.selectedSession = sessions[m.sessionCursor]
+	m.outputScroll = 0
+}
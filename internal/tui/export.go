@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"os"
+	"time"
+
+	"claude-session-manager/internal/config"
+	"claude-session-manager/internal/export"
+	"claude-session-manager/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// promptExport asks for a format and writes the selected session's
+// transcript to the exports directory.
+func (m *Model) promptExport() {
+	if m.selectedSession == nil {
+		return
+	}
+	sess := m.selectedSession
+	m.openPrompt("Export format (md, html, json)", string(export.Markdown), func(value string) tea.Cmd {
+		format, err := export.ParseFormat(value)
+		if err != nil {
+			m.notice = err.Error()
+			return nil
+		}
+		name := sess.ID + "-" + time.Now().Format("20060102-150405") + format.Extension()
+		path, err := config.DataPath("exports", name)
+		if err != nil {
+			m.notice = "Export failed: " + err.Error()
+			return nil
+		}
+		if err := writeExport(path, sess.State(), format); err != nil {
+			m.notice = "Export failed: " + err.Error()
+			return nil
+		}
+		m.notice = "Exported to " + path
+		return nil
+	})
+}
+
+func writeExport(path string, state session.SessionState, format export.Format) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := export.Write(file, state, format); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"fmt"
+
+	"claude-session-manager/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// promptLink asks for a link expression, starting from the selected session,
+// and then for an optional template to wrap each forwarded reply in.
+func (m *Model) promptLink() {
+	initial := ""
+	if m.selectedSession != nil {
+		initial = sessionRef(m.selectedSession) + " | "
+	}
+	m.openPrompt("Link (source | target [max N])", initial, func(expr string) tea.Cmd {
+		from, to, maxForwards, err := m.sessionManager.ParseLink(expr)
+		if err != nil {
+			m.notice = err.Error()
+			return nil
+		}
+		m.openPrompt("Template ({{.Output}} is the reply, blank forwards it as is)", "", func(tmpl string) tea.Cmd {
+			if err := m.sessionManager.AddLink(from, to, maxForwards, tmpl); err != nil {
+				m.notice = err.Error()
+				return nil
+			}
+			m.notice = "Forwarding replies from " + from.DisplayName() + " to " + to.DisplayName()
+			return nil
+		})
+		return nil
+	})
+}
+
+// unlinkSelected drops the links out of the selected session.
+func (m *Model) unlinkSelected() {
+	if m.selectedSession == nil {
+		return
+	}
+	if n := m.sessionManager.RemoveLinksFrom(m.selectedSession.ID); n > 0 {
+		m.notice = fmt.Sprintf("Removed %d links from %s", n, m.selectedSession.DisplayName())
+	} else {
+		m.notice = m.selectedSession.DisplayName() + " has no links"
+	}
+}
+
+// linkArrows describes where a session's replies are forwarded.
+func (m *Model) linkArrows(sess *session.Session) string {
+	var arrows string
+	for _, link := range m.sessionManager.LinksFrom(sess.ID) {
+		target := m.sessionManager.GetSession(link.To)
+		if target == nil {
+			continue
+		}
+		arrow := "→ " + sessionRef(target)
+		if link.Exhausted() {
+			arrow = "⇥ " + sessionRef(target)
+		}
+		arrows += " " + arrow
+	}
+	return arrows
+}
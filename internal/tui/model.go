@@ -1,10 +1,15 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 
+	"claude-session-manager/internal/config"
+	"claude-session-manager/internal/script"
 	"claude-session-manager/internal/session"
+	"claude-session-manager/internal/store"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -36,6 +41,36 @@ type Model struct {
 
 	// Output scrolling
 	outputScroll int
+	followOutput bool
+
+	// Session events
+	events      <-chan session.Event
+	unsubscribe func()
+
+	// Search
+	store     *store.Store
+	search    *searchView
+	highlight *store.Hit
+
+	// Shared scratchpads, and the one being edited in the input pane
+	scratch    *scratchpadView
+	editingPad string
+
+	// Workflows started from this TUI
+	workflows []*workflowRun
+
+	// Scripts bound to keys, and how to stop the ones running
+	scripts       []*script.Script
+	scriptCancels map[int]context.CancelFunc
+	nextScript    int
+
+	// Prompt templates, and the one being chosen or filled in
+	templateDir string
+	picker      *templateView
+
+	// Broadcast targets and how the last broadcast went for each
+	marked     map[string]bool
+	deliveries map[string]delivery
 
 	// UI components
 	styles *Styles
@@ -46,37 +81,46 @@ type Model struct {
 	inputPaneBounds   struct{ x, y, width, height int }
 
 	// Application state
+	prompt   *prompt
+	notice   string
 	quitting bool
 }
 
-func NewModel() *Model {
-	sessionManager := session.NewManager()
-
-	// Create some demo sessions
-	session1 := sessionManager.CreateSession("Main Session")
-	session1.SetStatus(session.StatusRunning)
-	session1.AddOutput("Welcome to ClaudePilot!")
-	session1.AddOutput("This is your main Claude session.")
-	session1.AddOutput("Type your commands in the input pane below.")
-
-	session2 := sessionManager.CreateSession("Analysis Session")
-	session2.SetStatus(session.StatusIdle)
-	session2.AddOutput("Analysis session ready for data processing.")
+// NewModel builds the TUI over sessionManager. When the manager has no
+// sessions yet, a few demo sessions are created.
+func NewModel(sessionManager *session.Manager) *Model {
+	// Sessions journaled by a run that never shut down cleanly are offered
+	// for recovery in place of the demo sessions.
+	var recoverable []string
+	if len(sessionManager.GetSessions()) == 0 {
+		if dir := sessionManager.JournalDir(); dir != "" {
+			recoverable, _ = session.RecoverableJournals(dir)
+		}
+		if len(recoverable) == 0 {
+			createDemoSessions(sessionManager)
+		}
+	}
 
-	session3 := sessionManager.CreateSession("Debug Session")
-	session3.SetStatus(session.StatusError)
-	session3.AddOutput("Error: Connection failed to Claude API")
-	session3.AddOutput("Retrying connection...")
+	model := newModel(sessionManager)
+	if len(recoverable) > 0 {
+		model.offerRecovery(len(recoverable))
+	}
+	return model
+}
 
+// newModel builds the TUI over whatever sessions the manager has.
+func newModel(sessionManager *session.Manager) *Model {
 	model := &Model{
-		sessionManager:  sessionManager,
-		selectedSession: session1,
-		sessionCursor:   0,
-		focusedPane:     SessionListPane,
-		styles:          NewStyles(),
-		inputHistory:    make([]string, 0),
-		historyIndex:    -1,
+		sessionManager: sessionManager,
+		sessionCursor:  0,
+		focusedPane:    SessionListPane,
+		styles:         NewStyles(),
+		inputHistory:   make([]string, 0),
+		historyIndex:   -1,
+		followOutput:   true,
 	}
+	model.syncSelection()
+	model.events, model.unsubscribe = sessionManager.Subscribe()
 
 	// Initialize panel bounds for mouse interaction
 	model.updatePanelBounds()
@@ -84,8 +128,22 @@ func NewModel() *Model {
 	return model
 }
 
+func createDemoSessions(sessionManager *session.Manager) {
+	sessionConfig := sessionManager.DefaultConfig()
+	session1 := sessionManager.CreateSession("Main Session", sessionConfig)
+	session1.AddOutput("Welcome to ClaudePilot!")
+	session1.AddOutput("This is your main Claude session.")
+	session1.AddOutput("Type your commands in the input pane below.")
+
+	session2 := sessionManager.CreateSession("Analysis Session", sessionConfig)
+	session2.AddOutput("Analysis session ready for data processing.")
+
+	session3 := sessionManager.CreateSession("Debug Session", sessionConfig)
+	session3.AddOutput("Debug session ready. Failed requests can be retried with 'r'.")
+}
+
 func (m *Model) Init() tea.Cmd {
-	return nil
+	return tea.Batch(listenForEvents(m.events), tickCmd())
 }
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -97,6 +155,18 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.prompt != nil {
+			return m.handlePromptKeys(msg)
+		}
+		if m.search != nil {
+			return m.handleSearchKeys(msg)
+		}
+		if m.scratch != nil {
+			return m.handleScratchpadKeys(msg)
+		}
+		if m.picker != nil {
+			return m.handleTemplateKeys(msg)
+		}
 		if m.showHelp {
 			return m.handleHelpKeys(msg)
 		}
@@ -104,6 +174,34 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.MouseMsg:
 		return m.handleMouse(msg)
+
+	case streamChunkMsg:
+		if m.followOutput && m.selectedSession != nil && m.selectedSession.ID == msg.sessionID {
+			m.scrollOutputToBottom()
+		}
+		return m, waitForStream(msg.stream)
+
+	case sendDoneMsg:
+		m.finishDelivery(msg.sessionID, msg.err)
+		if errors.Is(msg.err, session.ErrStopped) || errors.Is(msg.err, session.ErrBudgetExceeded) {
+			m.notice = msg.err.Error()
+		}
+		if m.followOutput && m.selectedSession != nil && m.selectedSession.ID == msg.sessionID {
+			m.scrollOutputToBottom()
+		}
+		return m, nil
+
+	case sessionEventMsg:
+		m.handleSessionEvent(msg.event)
+		return m, listenForEvents(m.events)
+
+	case scriptDoneMsg:
+		m.finishScript(msg)
+		return m, nil
+
+	case tickMsg:
+		m.checkWorkflows()
+		return m, tickCmd()
 	}
 
 	return m, nil
@@ -117,16 +215,67 @@ func (m *Model) handleHelpKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
 	return m, nil
 }
 
+// quit stops the running scripts and exits.
+func (m *Model) quit() (*Model, tea.Cmd) {
+	m.quitting = true
+	m.stopScripts()
+	m.unsubscribe()
+	return m, tea.Quit
+}
+
 func (m *Model) handleKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
+	m.notice = ""
+
 	switch msg.String() {
 	case "ctrl+c":
-		m.quitting = true
-		return m, tea.Quit
+		return m.quit()
 
 	case "?":
 		m.showHelp = true
 		return m, nil
 
+	case "ctrl+x":
+		if m.selectedSession != nil && m.selectedSession.Busy() {
+			m.selectedSession.Cancel()
+		}
+		return m, nil
+
+	case "ctrl+k":
+		if n := m.stopScripts(); n > 0 {
+			m.notice = fmt.Sprintf("Stopping %d script(s)", n)
+		} else {
+			m.notice = "No scripts are running"
+		}
+		return m, nil
+
+	case "ctrl+r":
+		return m, m.retrySelected()
+
+	case "ctrl+p":
+		m.promptPipe()
+		return m, nil
+
+	case "ctrl+s":
+		m.saveSnapshot()
+		return m, nil
+
+	case "ctrl+o":
+		m.loadLatestSnapshot()
+		return m, nil
+
+	case "ctrl+t":
+		if !m.openTemplates(false) {
+			m.notice = "No prompt templates found"
+		}
+		return m, nil
+
+	case "/":
+		// In the input pane "/" is just text.
+		if m.focusedPane != InputPane {
+			m.openSearch()
+			return m, nil
+		}
+
 	case "tab":
 		m.focusedPane = (m.focusedPane + 1) % 3
 		return m, nil
@@ -140,6 +289,10 @@ func (m *Model) handleKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if s := m.boundScript(msg.String()); s != nil {
+		return m, m.runScript(s)
+	}
+
 	switch m.focusedPane {
 	case SessionListPane:
 		return m.handleSessionListKeys(msg)
@@ -171,16 +324,28 @@ func (m *Model) handleSessionListKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
 		}
 
 	case "n":
-		sessionName := fmt.Sprintf("Session %d", len(sessions)+1)
-		newSession := m.sessionManager.CreateSession(sessionName)
-		newSession.AddOutput(fmt.Sprintf("New session '%s' created", sessionName))
-		m.selectedSession = newSession
-		m.sessionCursor = len(sessions)
-		m.outputScroll = 0
+		if !m.openTemplates(true) {
+			m.newSession("")
+		}
 
-	case "d", "x":
+	case "d", "x", "D":
 		if len(sessions) > 0 && m.selectedSession != nil {
-			m.sessionManager.RemoveSession(m.selectedSession.ID)
+			transcriptPath := ""
+			if msg.String() == "D" {
+				path, err := config.DataPath("transcripts", m.selectedSession.ID+".json")
+				if err != nil {
+					m.notice = "Kill failed: " + err.Error()
+					return m, nil
+				}
+				transcriptPath = path
+			}
+			if err := m.sessionManager.KillSession(m.selectedSession.ID, transcriptPath); err != nil {
+				m.notice = "Kill failed: " + err.Error()
+				return m, nil
+			}
+			if transcriptPath != "" {
+				m.notice = "Transcript saved to " + transcriptPath
+			}
 			sessions = m.sessionManager.GetSessions()
 			if len(sessions) == 0 {
 				m.selectedSession = nil
@@ -194,17 +359,74 @@ func (m *Model) handleSessionListKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
 			m.outputScroll = 0
 		}
 
+	case "r":
+		return m, m.retrySelected()
+
+	case "a":
+		if m.selectedSession != nil {
+			id := m.selectedSession.ID
+			m.openPrompt("Alias", m.selectedSession.GetAlias(), func(alias string) tea.Cmd {
+				if err := m.sessionManager.SetAlias(id, alias); err != nil {
+					m.notice = err.Error()
+				}
+				return nil
+			})
+		}
+
 	case "s":
 		if m.selectedSession != nil {
-			currentStatus := m.selectedSession.GetStatus()
-			if currentStatus == session.StatusRunning {
-				m.selectedSession.SetStatus(session.StatusStopped)
-				m.selectedSession.AddOutput("Session stopped by user")
+			var err error
+			if m.selectedSession.GetStatus() == session.StatusStopped {
+				err = m.selectedSession.Start(context.Background())
 			} else {
-				m.selectedSession.SetStatus(session.StatusRunning)
-				m.selectedSession.AddOutput("Session started")
+				err = m.selectedSession.Stop("")
+			}
+			if err != nil {
+				m.notice = err.Error()
+			}
+		}
+
+	case "e":
+		m.promptExport()
+
+	case "I":
+		m.importClaudeLogs()
+
+	case "A":
+		m.adoptSelected()
+
+	case " ":
+		m.toggleMark()
+
+	case "c":
+		m.openScratchpads()
+
+	case "X":
+		m.promptScript()
+
+	case "W":
+		m.promptWorkflow()
+
+	case "L":
+		m.promptLink()
+
+	case "U":
+		m.unlinkSelected()
+
+	case "S":
+		// Stop everything if anything is live, otherwise start everything
+		anyLive := false
+		for _, sess := range sessions {
+			if sess.GetStatus() != session.StatusStopped {
+				anyLive = true
+				break
 			}
 		}
+		if anyLive {
+			m.sessionManager.StopAll("stopped by user")
+		} else if err := m.sessionManager.StartAll(context.Background()); err != nil {
+			m.notice = err.Error()
+		}
 	}
 
 	return m, nil
@@ -215,11 +437,7 @@ func (m *Model) handleOutputKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
 		return m, nil
 	}
 
-	output := m.selectedSession.GetOutput()
-	maxScroll := len(output) - m.getOutputHeight() + 2
-	if maxScroll < 0 {
-		maxScroll = 0
-	}
+	maxScroll := m.maxOutputScroll()
 
 	switch msg.String() {
 	case "j", "down":
@@ -238,6 +456,7 @@ func (m *Model) handleOutputKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
 	case "G":
 		m.outputScroll = maxScroll
 	}
+	m.followOutput = m.outputScroll >= maxScroll
 
 	return m, nil
 }
@@ -248,27 +467,42 @@ func (m *Model) handleInputKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
 		// Enter creates a new line
 		m.inputValue += "\n"
 
+	case "esc":
+		if m.editingPad != "" {
+			m.notice = "Discarded changes to " + m.editingPad
+			m.cancelScratchpadEdit()
+		}
+
 	case "ctrl+enter":
-		// Ctrl+Enter submits the input
-		if strings.TrimSpace(m.inputValue) != "" && m.selectedSession != nil {
+		if m.editingPad != "" {
+			m.saveScratchpad()
+			return m, nil
+		}
+		// Ctrl+Enter submits the input, to every marked session if any are
+		if strings.TrimSpace(m.inputValue) != "" && len(m.markedSessions()) > 0 {
 			m.inputHistory = append(m.inputHistory, m.inputValue)
 			m.historyIndex = -1
 
-			// Display the user input with proper formatting
-			inputLines := strings.Split(m.inputValue, "\n")
-			for i, line := range inputLines {
-				if i == 0 {
-					m.selectedSession.AddOutput(fmt.Sprintf("> %s", line))
-				} else {
-					m.selectedSession.AddOutput(fmt.Sprintf("  %s", line))
-				}
+			cmd := m.broadcast(m.inputValue)
+
+			m.inputValue = ""
+			m.scrollOutputToBottom()
+			return m, cmd
+		}
+		if strings.TrimSpace(m.inputValue) != "" && m.selectedSession != nil {
+			if m.selectedSession.Busy() {
+				return m, nil
 			}
 
-			// Send to Claude session
-			m.selectedSession.SendInput(m.inputValue)
+			m.inputHistory = append(m.inputHistory, m.inputValue)
+			m.historyIndex = -1
+
+			// Send to Claude session without blocking the UI
+			cmd := sendInputCmd(m.selectedSession, m.inputValue)
 
 			m.inputValue = ""
-			m.outputScroll = len(m.selectedSession.GetOutput())
+			m.scrollOutputToBottom()
+			return m, cmd
 		}
 
 	case "up":
@@ -321,6 +555,30 @@ func (m *Model) handleInputKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
 	return m, nil
 }
 
+// newSession creates a session with the default config and selects it. An
+// empty name is numbered after the existing sessions.
+func (m *Model) newSession(name string) *session.Session {
+	sessions := m.sessionManager.GetSessions()
+	if name == "" {
+		name = fmt.Sprintf("Session %d", len(sessions)+1)
+	}
+	newSession := m.sessionManager.CreateSession(name, m.sessionManager.DefaultConfig())
+	newSession.AddOutput(fmt.Sprintf("New session '%s' created", name))
+	m.selectedSession = newSession
+	m.sessionCursor = len(sessions)
+	m.outputScroll = 0
+	return newSession
+}
+
+// retrySelected resends the selected session's last prompt after a failure.
+func (m *Model) retrySelected() tea.Cmd {
+	if m.selectedSession == nil || m.selectedSession.Busy() || m.selectedSession.GetLastError() == nil {
+		return nil
+	}
+	m.scrollOutputToBottom()
+	return retryLastCmd(m.selectedSession)
+}
+
 func (m *Model) updatePanelBounds() {
 	if m.width < 60 || m.height < 15 {
 		return
@@ -370,6 +628,7 @@ func (m *Model) handleMouse(msg tea.MouseMsg) (*Model, tea.Cmd) {
 		if m.isPointInBounds(msg.X, msg.Y, m.outputPaneBounds) && m.focusedPane == OutputPane {
 			if m.outputScroll > 0 {
 				m.outputScroll--
+				m.followOutput = false
 			}
 		} else if m.isPointInBounds(msg.X, msg.Y, m.sessionListBounds) && m.focusedPane == SessionListPane {
 			sessions := m.sessionManager.GetSessions()
@@ -382,15 +641,9 @@ func (m *Model) handleMouse(msg tea.MouseMsg) (*Model, tea.Cmd) {
 
 	case tea.MouseWheelDown:
 		if m.isPointInBounds(msg.X, msg.Y, m.outputPaneBounds) && m.focusedPane == OutputPane {
-			if m.selectedSession != nil {
-				output := m.selectedSession.GetOutput()
-				maxScroll := len(output) - m.getOutputHeight() + 2
-				if maxScroll < 0 {
-					maxScroll = 0
-				}
-				if m.outputScroll < maxScroll {
-					m.outputScroll++
-				}
+			if m.selectedSession != nil && m.outputScroll < m.maxOutputScroll() {
+				m.outputScroll++
+				m.followOutput = m.outputScroll >= m.maxOutputScroll()
 			}
 		} else if m.isPointInBounds(msg.X, msg.Y, m.sessionListBounds) && m.focusedPane == SessionListPane {
 			sessions := m.sessionManager.GetSessions()
@@ -436,6 +689,22 @@ func (m *Model) getOutputHeight() int {
 	return m.height - 8 // Account for borders, input pane, and title
 }
 
+func (m *Model) maxOutputScroll() int {
+	if m.selectedSession == nil {
+		return 0
+	}
+	maxScroll := len(m.selectedSession.GetOutput()) - m.getOutputHeight() + 2
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	return maxScroll
+}
+
+func (m *Model) scrollOutputToBottom() {
+	m.outputScroll = m.maxOutputScroll()
+	m.followOutput = true
+}
+
 func (m *Model) View() string {
 	if m.quitting {
 		return m.styles.InfoText.Render("Thanks for using ClaudePilot! 👋")
@@ -464,7 +733,10 @@ func (m *Model) renderMain() string {
 
 	main := lipgloss.JoinHorizontal(lipgloss.Top, sessionList, rightColumn)
 
-	title := m.styles.TitleStyle.Render("ClaudePilot - Claude Session Manager")
+	title := lipgloss.JoinHorizontal(lipgloss.Top,
+		m.styles.TitleStyle.Render("ClaudePilot - Claude Session Manager"),
+		m.renderStatusBar(),
+	)
 	footer := m.renderFooter()
 
 	return lipgloss.JoinVertical(lipgloss.Top, title, main, footer)
@@ -484,9 +756,32 @@ func (m *Model) renderSessionList(width, height int) string {
 			style = m.styles.SessionInactive
 		}
 
-		line := fmt.Sprintf("%s %s", status, sess.Name)
-		if sess.LastMessage != "" && len(sess.LastMessage) > 20 {
-			preview := sess.LastMessage[:17] + "..."
+		line := fmt.Sprintf("%s %s", status, sess.DisplayName())
+		if m.marked[sess.ID] {
+			line = m.styles.StatusIdle.Render("✓") + " " + line
+		} else if len(m.marked) > 0 {
+			line = "  " + line
+		}
+		if indicator := m.deliveryIndicator(sess); indicator != "" {
+			line += " " + indicator
+		}
+		if wait := sess.RetryCountdown(); wait > 0 {
+			line += " " + m.styles.InfoText.Render(fmt.Sprintf("retry in %ds", int(wait.Seconds()+0.5)))
+		}
+		if usage, cost := sess.GetUsage(); usage.Total() > 0 {
+			line += " " + m.styles.InfoText.Render(formatUsage(usage, cost))
+		}
+		if sess.GetBudgetWarning() != "" {
+			line += " " + m.styles.SessionStopped.Render("⚠")
+		}
+		if badge := m.workflowBadge(sess); badge != "" {
+			line += " " + badge
+		}
+		if arrows := m.linkArrows(sess); arrows != "" {
+			line += m.styles.InfoText.Render(arrows)
+		}
+		if last := []rune(sess.GetLastMessage()); len(last) > 20 {
+			preview := string(last[:17]) + "..."
 			line += fmt.Sprintf("\n  %s", m.styles.InfoText.Render(preview))
 		}
 
@@ -521,12 +816,38 @@ func (m *Model) renderSessionList(width, height int) string {
 }
 
 func (m *Model) renderOutputPane(width, height int) string {
-	var content string
+	var content, settings string
 
-	if m.selectedSession == nil {
+	title := "Output"
+	if m.focusedPane == OutputPane {
+		title = "● Output"
+	}
+
+	switch {
+	case m.search != nil:
+		title = fmt.Sprintf("Search: %s", m.search.query)
+		settings = m.styles.InfoText.Render(fmt.Sprintf("%d matches · Enter: jump  Esc: close", len(m.search.hits)))
+		content = m.renderSearch(height)
+	case m.scratch != nil:
+		title = "Scratchpads"
+		settings = m.styles.InfoText.Render("n: New  e: Edit  a: Attach  h/l: Versions  R: Restore  Esc: close")
+		content = m.renderScratchpads(height)
+	case m.picker != nil && m.picker.form != nil:
+		title = "Template: " + m.picker.form.tmpl.Name
+		settings = m.styles.InfoText.Render("Tab/↑/↓: Field  Enter: Next/OK  Esc: Back  * required")
+		content = m.renderTemplates(height)
+	case m.picker != nil:
+		title = "Templates"
+		settings = m.styles.InfoText.Render("j/k: Move  Enter: Choose  Esc: Close")
+		content = m.renderTemplates(height)
+	case m.selectedSession == nil:
 		content = m.styles.InfoText.Render("Select a session to view output")
-	} else {
+	default:
 		output := m.selectedSession.GetOutput()
+		settings = m.styles.InfoText.Render(m.selectedSession.GetConfig().Summary())
+		if sendErr := m.selectedSession.GetLastError(); sendErr != nil && m.selectedSession.GetStatus() == session.StatusError {
+			settings = m.styles.ErrorText.Render(formatSendError(sendErr))
+		}
 
 		startLine := m.outputScroll
 		endLine := startLine + height - 3
@@ -536,6 +857,10 @@ func (m *Model) renderOutputPane(width, height int) string {
 
 		var lines []string
 		for i := startLine; i < endLine; i++ {
+			if m.highlight != nil && m.highlight.SessionID == m.selectedSession.ID && m.highlight.Line == i {
+				lines = append(lines, m.styles.SearchMatch.Render(output[i]))
+				continue
+			}
 			lines = append(lines, output[i])
 		}
 
@@ -552,16 +877,11 @@ func (m *Model) renderOutputPane(width, height int) string {
 		borderStyle = m.styles.InactiveBorder
 	}
 
-	title := "Output"
-	if m.focusedPane == OutputPane {
-		title = "● Output"
-	}
-
 	return borderStyle.
 		Width(width).
 		Height(height).
 		Render(lipgloss.JoinVertical(lipgloss.Top,
-			m.styles.TitleStyle.Render(title),
+			lipgloss.JoinHorizontal(lipgloss.Top, m.styles.TitleStyle.Render(title), settings),
 			m.styles.OutputText.Render(content),
 		))
 }
@@ -611,6 +931,11 @@ func (m *Model) renderInputPane(width, height int) string {
 	if m.focusedPane == InputPane {
 		title = "● Input"
 	}
+	if m.editingPad != "" {
+		title += " · editing scratchpad " + m.editingPad + " (Ctrl+Enter: Save, Esc: Discard)"
+	} else if marked := len(m.markedSessions()); marked > 0 {
+		title += fmt.Sprintf(" → %d marked sessions", marked)
+	}
 
 	return borderStyle.
 		Width(width).
@@ -621,25 +946,93 @@ func (m *Model) renderInputPane(width, height int) string {
 		))
 }
 
+func (m *Model) renderStatusBar() string {
+	counts := make(map[session.Status]int)
+	sessions := m.sessionManager.GetSessions()
+	for _, sess := range sessions {
+		counts[sess.GetStatus()]++
+	}
+
+	parts := []string{
+		fmt.Sprintf("Sessions: %d", len(sessions)),
+		fmt.Sprintf("Running: %d", counts[session.StatusRunning]+counts[session.StatusConnecting]),
+	}
+	if counts[session.StatusError] > 0 {
+		parts = append(parts, m.styles.ErrorText.Render(fmt.Sprintf("Errors: %d", counts[session.StatusError])))
+	}
+
+	if m.notice != "" {
+		parts = append(parts, m.styles.SessionStopped.Render(m.notice))
+	}
+
+	return m.styles.InfoText.Render(strings.Join(parts, "  ·  "))
+}
+
 func (m *Model) renderFooter() string {
+	if m.prompt != nil {
+		return m.renderPrompt()
+	}
+
 	keys := []string{
 		"Tab: Switch panes",
 		"Mouse: Click panels/scroll",
 		"?: Help",
+		"Ctrl+X: Cancel request",
+		"Ctrl+K: Stop scripts",
+		"Ctrl+R: Retry",
+		"Ctrl+P: Pipe",
+		"Ctrl+S/O: Save/Load snapshot",
+		"Ctrl+T: Template",
 		"Ctrl+C: Quit",
 	}
 
 	if m.focusedPane == SessionListPane {
-		keys = append(keys, "n: New", "d: Delete", "s: Start/Stop", "Click: Select session")
+		keys = append(keys, "n: New", "a: Alias", "d: Kill", "D: Kill+save", "s/S: Start/Stop (all)", "Space: Mark", "c: Scratchpads", "W: Workflow", "X: Script", "e: Export", "I/A: Import/Adopt", "L/U: Link/Unlink", "/: Search", "Click: Select session")
 	} else if m.focusedPane == InputPane {
-		keys = append(keys, "Enter: New line", "Ctrl+Enter: Send", "↑/↓: History")
+		keys = append(keys, "Enter: New line", "Ctrl+Enter: Send (to marked)", "↑/↓: History")
 	} else if m.focusedPane == OutputPane {
-		keys = append(keys, "j/k: Scroll", "g/G: Top/Bottom", "Wheel: Scroll")
+		keys = append(keys, "j/k: Scroll", "g/G: Top/Bottom", "/: Search", "Wheel: Scroll")
+	}
+
+	usage, cost := m.sessionManager.TotalUsage()
+	keys = append(keys, "Total: "+formatUsage(usage, cost))
+	switch state, reason := m.sessionManager.BudgetStatus(); state {
+	case session.BudgetSoftExceeded:
+		keys = append(keys, m.styles.SessionStopped.Render("⚠ "+reason))
+	case session.BudgetHardExceeded:
+		keys = append(keys, m.styles.ErrorText.Render("■ "+reason))
 	}
 
 	return m.styles.InfoText.Render(strings.Join(keys, "  |  "))
 }
 
+func formatSendError(err *session.SendError) string {
+	text := "✗ " + string(err.Kind)
+	if err.StatusCode != 0 {
+		text += fmt.Sprintf(" %d", err.StatusCode)
+	}
+	text += ": " + err.Message
+	if err.Attempts > 1 {
+		text += fmt.Sprintf(" (%d attempts)", err.Attempts)
+	}
+	return text + " · r: retry"
+}
+
+func formatUsage(usage session.Usage, cost float64) string {
+	return fmt.Sprintf("$%.4f · %s tok", cost, formatTokens(usage.Total()))
+}
+
+func formatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
 func (m *Model) renderHelp() string {
 	help := []string{
 		m.styles.HelpTitle.Render("ClaudePilot Help"),
@@ -647,8 +1040,23 @@ func (m *Model) renderHelp() string {
 		m.styles.HelpKey.Render("Global Keys:"),
 		"  Tab / Shift+Tab    Switch between panes",
 		"  ?                  Show/hide this help",
+		"  Ctrl+X             Cancel the selected session's request",
+		"  Ctrl+K             Stop the running scripts",
+		"  Ctrl+R             Retry the selected session's last failed prompt",
+		"  Ctrl+P             Pipe a session's output into another session",
+		"  Ctrl+S             Save a snapshot of all sessions",
+		"  Ctrl+O             Load the latest snapshot",
+		"  Ctrl+T             Fill in a prompt template and insert it into the input",
+		"  /                  Search open and archived transcripts",
 		"  Ctrl+C             Quit application",
 		"",
+	}
+	if scripts := m.scriptHelp(); len(scripts) > 0 {
+		help = append(help, m.styles.HelpKey.Render("Scripts:"))
+		help = append(help, scripts...)
+		help = append(help, "")
+	}
+	help = append(help,
 		m.styles.HelpKey.Render("Mouse Controls:"),
 		"  Click              Focus panel and select items",
 		"  Scroll Wheel       Navigate lists and scroll output",
@@ -657,9 +1065,22 @@ func (m *Model) renderHelp() string {
 		m.styles.HelpKey.Render("Session List (Left Pane):"),
 		"  j / ↓              Move cursor down",
 		"  k / ↑              Move cursor up",
-		"  n                  Create new session",
-		"  d / x              Delete selected session",
+		"  n                  Create new session, optionally from a prompt template",
+		"  a                  Set an alias for the selected session",
+		"  d / x              Kill selected session",
+		"  D                  Kill selected session and save its transcript",
 		"  s                  Start/stop selected session",
+		"  S                  Start/stop all sessions",
+		"  Space              Mark/unmark session for broadcast",
+		"  c                  View, edit and attach shared scratchpads",
+		"  W                  Load and run a workflow file",
+		"  X                  Run a Lua script file",
+		"  e                  Export transcript as Markdown, HTML or JSON",
+		"  I                  Import conversations from ~/.claude/projects",
+		"  A                  Adopt an imported conversation and resume it",
+		"  L                  Forward every reply of a session to another",
+		"  U                  Remove the links out of the selected session",
+		"  r                  Retry last failed prompt",
 		"  Click session      Select session",
 		"",
 		m.styles.HelpKey.Render("Output Pane (Top Right):"),
@@ -671,13 +1092,13 @@ func (m *Model) renderHelp() string {
 		"",
 		m.styles.HelpKey.Render("Input Pane (Bottom Right):"),
 		"  Enter              Create new line",
-		"  Ctrl+Enter         Send message to Claude",
+		"  Ctrl+Enter         Send message to Claude, or to every marked session",
 		"  ↑ / ↓              Navigate command history",
 		"  Backspace          Delete character",
 		"  Ctrl+Backspace     Delete word backward",
 		"",
 		m.styles.InfoText.Render("Press '?' or 'Esc' to close this help"),
-	}
+	)
 
 	content := strings.Join(help, "\n")
 
@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"claude-session-manager/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sessionRef is how a session is referred to in typed commands: its alias
+// when it has one, otherwise its ID.
+func sessionRef(sess *session.Session) string {
+	if alias := sess.GetAlias(); alias != "" {
+		return "@" + alias
+	}
+	return sess.ID
+}
+
+// promptPipe asks for a pipe expression, starting from the selected
+// session, and then for an optional template to wrap the selection in.
+func (m *Model) promptPipe() {
+	initial := ""
+	if m.selectedSession != nil {
+		initial = sessionRef(m.selectedSession) + " reply | "
+	}
+	m.openPrompt("Pipe (source [reply|last N|all] | target)", initial, func(expr string) tea.Cmd {
+		pipe, err := m.sessionManager.ParsePipe(expr)
+		if err != nil {
+			m.notice = err.Error()
+			return nil
+		}
+		m.openPrompt("Template ({{.Output}} is the selection, blank sends it as is)", "", func(tmpl string) tea.Cmd {
+			pipe.Template = tmpl
+			return m.startPipe(pipe)
+		})
+		return nil
+	})
+}
+
+func (m *Model) startPipe(pipe session.Pipe) tea.Cmd {
+	// Check the selection and template now so mistakes show up as a notice
+	// rather than as a failed request on the target.
+	if _, err := pipe.Prompt(); err != nil {
+		m.notice = err.Error()
+		return nil
+	}
+	if pipe.To.Busy() {
+		m.notice = pipe.To.DisplayName() + " is busy"
+		return nil
+	}
+	m.notice = "Piping " + pipe.Selection.String() + " from " + pipe.From.DisplayName() + " to " + pipe.To.DisplayName()
+	m.selectedSession = pipe.To
+	m.syncSelection()
+	m.scrollOutputToBottom()
+	return pipeCmd(pipe)
+}
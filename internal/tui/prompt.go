@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// prompt is a one-line input shown in place of the footer. While it is open
+// it receives every key.
+type prompt struct {
+	label    string
+	value    string
+	onSubmit func(value string) tea.Cmd
+	// onCancel, if set, runs when the prompt is dismissed with Esc.
+	onCancel func()
+}
+
+func (m *Model) openPrompt(label, initial string, onSubmit func(string) tea.Cmd) {
+	m.prompt = &prompt{label: label, value: initial, onSubmit: onSubmit}
+}
+
+func (m *Model) handlePromptKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
+	p := m.prompt
+	switch msg.String() {
+	case "ctrl+c":
+		return m.quit()
+
+	case "esc":
+		m.prompt = nil
+		if p.onCancel != nil {
+			p.onCancel()
+		}
+
+	case "enter":
+		m.prompt = nil
+		return m, p.onSubmit(strings.TrimSpace(p.value))
+
+	case "backspace":
+		if len(p.value) > 0 {
+			runes := []rune(p.value)
+			p.value = string(runes[:len(runes)-1])
+		}
+
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			p.value += string(msg.Runes)
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) renderPrompt() string {
+	return m.styles.InputPrompt.Render(m.prompt.label+": ") +
+		m.styles.InputField.Render(m.prompt.value+"█") +
+		m.styles.InfoText.Render("  Enter: OK  Esc: Cancel")
+}
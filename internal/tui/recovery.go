@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"claude-session-manager/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// offerRecovery asks whether to rebuild the sessions whose journals were left
+// open by a crashed or killed run. Declining closes those journals and starts
+// with the demo sessions instead.
+func (m *Model) offerRecovery(count int) {
+	label := fmt.Sprintf("Recover %d session(s) from an interrupted run? [Y/n]", count)
+	m.openPrompt(label, "", func(answer string) tea.Cmd {
+		switch strings.ToLower(answer) {
+		case "", "y", "yes":
+			m.recoverJournals()
+		default:
+			m.declineRecovery()
+		}
+		return nil
+	})
+	m.prompt.onCancel = m.declineRecovery
+}
+
+func (m *Model) recoverJournals() {
+	recovered, err := m.sessionManager.RecoverJournals()
+	if err != nil {
+		m.notice = "Recovery failed: " + err.Error()
+	} else {
+		m.notice = fmt.Sprintf("Recovered %d sessions; press 's' to start one", len(recovered))
+	}
+	if len(m.sessionManager.GetSessions()) == 0 {
+		createDemoSessions(m.sessionManager)
+	}
+	m.syncSelection()
+}
+
+func (m *Model) declineRecovery() {
+	if err := session.DiscardJournals(m.sessionManager.JournalDir()); err != nil {
+		m.notice = "Discarding journals failed: " + err.Error()
+	}
+	createDemoSessions(m.sessionManager)
+	m.syncSelection()
+}
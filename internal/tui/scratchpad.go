@@ -0,0 +1,200 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"claude-session-manager/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// scratchpadView lists the shared scratchpads in place of the output pane,
+// with the content of the one under the cursor. back counts versions back
+// from the current one.
+type scratchpadView struct {
+	cursor int
+	back   int
+}
+
+func (m *Model) openScratchpads() {
+	m.scratch = &scratchpadView{}
+}
+
+// selectedScratchpad returns the scratchpad under the cursor and the version
+// being viewed.
+func (m *Model) selectedScratchpad() (session.Scratchpad, session.ScratchpadVersion, bool) {
+	pads := m.sessionManager.Scratchpads()
+	v := m.scratch
+	if len(pads) == 0 {
+		return session.Scratchpad{}, session.ScratchpadVersion{}, false
+	}
+	if v.cursor >= len(pads) {
+		v.cursor = len(pads) - 1
+	}
+	pad := pads[v.cursor]
+	if v.back >= len(pad.Versions) {
+		v.back = max(len(pad.Versions)-1, 0)
+	}
+	if len(pad.Versions) == 0 {
+		return pad, session.ScratchpadVersion{}, true
+	}
+	return pad, pad.Versions[len(pad.Versions)-1-v.back], true
+}
+
+func (m *Model) handleScratchpadKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
+	v := m.scratch
+	pad, version, ok := m.selectedScratchpad()
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m.quit()
+
+	case "esc", "q":
+		m.scratch = nil
+
+	case "j", "down":
+		if v.cursor < len(m.sessionManager.Scratchpads())-1 {
+			v.cursor++
+			v.back = 0
+		}
+
+	case "k", "up":
+		if v.cursor > 0 {
+			v.cursor--
+			v.back = 0
+		}
+
+	case "h", "left":
+		if ok && v.back < len(pad.Versions)-1 {
+			v.back++
+		}
+
+	case "l", "right":
+		if v.back > 0 {
+			v.back--
+		}
+
+	case "n":
+		m.openPrompt("New scratchpad", "", func(name string) tea.Cmd {
+			if err := m.sessionManager.CreateScratchpad(name); err != nil {
+				m.notice = err.Error()
+				return nil
+			}
+			m.editScratchpad(name, "")
+			return nil
+		})
+
+	case "e", "enter":
+		if ok {
+			m.editScratchpad(pad.Name, version.Content)
+		}
+
+	case "R":
+		// Bring back an old version by writing it again.
+		if ok && v.back > 0 {
+			saved, err := m.sessionManager.WriteScratchpad(pad.Name, version.Content, nil)
+			if err != nil {
+				m.notice = err.Error()
+				break
+			}
+			v.back = 0
+			m.notice = fmt.Sprintf("Restored %s v%d as v%d", pad.Name, version.Version, saved.Version)
+		}
+
+	case "a":
+		if ok && m.selectedSession != nil {
+			m.toggleScratchpad(m.selectedSession, pad.Name)
+		}
+	}
+	return m, nil
+}
+
+// toggleScratchpad attaches the scratchpad to the session, or detaches it
+// if it already was.
+func (m *Model) toggleScratchpad(sess *session.Session, name string) {
+	if m.sessionManager.DetachScratchpad(sess, name) {
+		m.notice = "Detached " + name + " from " + sess.DisplayName()
+		return
+	}
+	if err := m.sessionManager.AttachScratchpad(sess, name); err != nil {
+		m.notice = err.Error()
+		return
+	}
+	m.notice = "Attached " + name + " to " + sess.DisplayName()
+}
+
+// editScratchpad moves the scratchpad's content into the input pane, where
+// Ctrl+Enter saves it as a new version and Esc abandons the edit.
+func (m *Model) editScratchpad(name, content string) {
+	m.scratch = nil
+	m.editingPad = name
+	m.inputValue = content
+	m.focusedPane = InputPane
+}
+
+func (m *Model) saveScratchpad() {
+	version, err := m.sessionManager.WriteScratchpad(m.editingPad, m.inputValue, nil)
+	if err != nil {
+		m.notice = err.Error()
+		return
+	}
+	m.notice = fmt.Sprintf("Saved %s as v%d", m.editingPad, version.Version)
+	m.cancelScratchpadEdit()
+}
+
+func (m *Model) cancelScratchpadEdit() {
+	name := m.editingPad
+	m.editingPad = ""
+	m.inputValue = ""
+	m.openScratchpads()
+	for i, pad := range m.sessionManager.Scratchpads() {
+		if pad.Name == name {
+			m.scratch.cursor = i
+		}
+	}
+}
+
+func (m *Model) renderScratchpads(height int) string {
+	pads := m.sessionManager.Scratchpads()
+	if len(pads) == 0 {
+		return m.styles.InfoText.Render("No scratchpads yet. Press 'n' to create one.")
+	}
+	pad, version, _ := m.selectedScratchpad()
+
+	var attached []string
+	if m.selectedSession != nil {
+		attached = m.selectedSession.GetConfig().Scratchpads
+	}
+
+	var lines []string
+	for i, p := range pads {
+		mark := " "
+		if slices.Contains(attached, p.Name) {
+			mark = "●"
+		}
+		current := p.Current()
+		line := fmt.Sprintf("%s %s  v%d", mark, p.Name, current.Version)
+		if current.Version > 0 {
+			line += fmt.Sprintf(" · %s · %s", current.AuthorName, current.Time.Local().Format("15:04:05"))
+		}
+		if i == m.scratch.cursor {
+			line = m.styles.SearchMatch.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "")
+	if version.Version == 0 {
+		lines = append(lines, m.styles.InfoText.Render(pad.Name+" is empty"))
+	} else {
+		lines = append(lines, m.styles.InfoText.Render(fmt.Sprintf("%s v%d of %d, written by %s at %s",
+			pad.Name, version.Version, len(pad.Versions), version.AuthorName, version.Time.Local().Format("2006-01-02 15:04:05"))))
+		lines = append(lines, strings.Split(version.Content, "\n")...)
+	}
+
+	if visible := height - 3; len(lines) > visible && visible > 0 {
+		lines = lines[:visible]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"claude-session-manager/internal/script"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// scriptDoneMsg reports a script that has finished, with what it printed.
+type scriptDoneMsg struct {
+	id     int
+	name   string
+	output string
+	err    error
+}
+
+// LoadScripts binds the scripts in dir to the keys their headers name. Files
+// that fail to load are reported as a notice.
+func (m *Model) LoadScripts(dir string) {
+	scripts, err := script.LoadDir(dir)
+	m.scripts = scripts
+	if err != nil {
+		m.notice = err.Error()
+	}
+}
+
+// boundScript returns the script bound to key, if any.
+func (m *Model) boundScript(key string) *script.Script {
+	for _, s := range m.scripts {
+		if s.Key == key {
+			return s
+		}
+	}
+	return nil
+}
+
+func (m *Model) promptScript() {
+	m.openPrompt("Script file", "", func(path string) tea.Cmd {
+		if path == "" {
+			return nil
+		}
+		s, err := script.Load(path)
+		if err != nil {
+			m.notice = err.Error()
+			return nil
+		}
+		return m.runScript(s)
+	})
+}
+
+// runScript runs s in the background against the selected session, until it
+// ends or stopScripts cancels it.
+func (m *Model) runScript(s *script.Script) tea.Cmd {
+	env := script.Env{Manager: m.sessionManager, Selected: m.selectedSession}
+	ctx, cancel := context.WithCancel(context.Background())
+	if m.scriptCancels == nil {
+		m.scriptCancels = make(map[int]context.CancelFunc)
+	}
+	id := m.nextScript
+	m.nextScript++
+	m.scriptCancels[id] = cancel
+	m.notice = fmt.Sprintf("Running script %s", s.Name)
+	return func() tea.Msg {
+		var output bytes.Buffer
+		env.Output = &output
+		err := s.Run(ctx, env)
+		return scriptDoneMsg{id: id, name: s.Name, output: output.String(), err: err}
+	}
+}
+
+// stopScripts cancels every running script and returns how many there were.
+// Their requests are cancelled with them.
+func (m *Model) stopScripts() int {
+	for _, cancel := range m.scriptCancels {
+		cancel()
+	}
+	return len(m.scriptCancels)
+}
+
+// finishScript shows how a script ended: its error, or the last line it
+// printed.
+func (m *Model) finishScript(msg scriptDoneMsg) {
+	if cancel, ok := m.scriptCancels[msg.id]; ok {
+		cancel()
+		delete(m.scriptCancels, msg.id)
+	}
+	lines := strings.Split(strings.TrimRight(msg.output, "\n"), "\n")
+	switch last := lines[len(lines)-1]; {
+	case errors.Is(msg.err, context.Canceled):
+		m.notice = fmt.Sprintf("Script %s stopped", msg.name)
+	case msg.err != nil:
+		m.notice = fmt.Sprintf("Script %s failed: %v", msg.name, msg.err)
+	case last != "":
+		m.notice = fmt.Sprintf("%s: %s", msg.name, last)
+	default:
+		m.notice = fmt.Sprintf("Script %s finished", msg.name)
+	}
+}
+
+// scriptHelp lists the bound scripts for the help screen.
+func (m *Model) scriptHelp() []string {
+	var lines []string
+	for _, s := range m.scripts {
+		if s.Key != "" {
+			lines = append(lines, fmt.Sprintf("  %-18s %s", s.Key, filepath.Base(s.Path)))
+		}
+	}
+	return lines
+}
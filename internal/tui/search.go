@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"claude-session-manager/internal/store"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const maxSearchHits = 200
+
+// searchView lists the hits of a search in place of the output pane.
+type searchView struct {
+	query  string
+	hits   []store.Hit
+	cursor int
+}
+
+// SetStore enables "/" search over st, which also receives the open
+// sessions' transcripts each time a search runs.
+func (m *Model) SetStore(st *store.Store) {
+	m.store = st
+	if skipped := st.Skipped(); len(skipped) > 0 {
+		m.notice = fmt.Sprintf("Skipped %d unreadable file(s) in the session archive", len(skipped))
+	}
+}
+
+func (m *Model) openSearch() {
+	if m.store == nil {
+		m.notice = "Search is not available without a session store"
+		return
+	}
+	m.openPrompt("Search", "", func(query string) tea.Cmd {
+		if query != "" {
+			m.runSearch(query)
+		}
+		return nil
+	})
+}
+
+func (m *Model) runSearch(query string) {
+	for _, sess := range m.sessionManager.GetSessions() {
+		m.store.Index(sess.State())
+	}
+	hits := m.store.Search(query, maxSearchHits)
+	if len(hits) == 0 {
+		m.notice = fmt.Sprintf("No matches for %q", query)
+		return
+	}
+	m.search = &searchView{query: query, hits: hits}
+}
+
+func (m *Model) handleSearchKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
+	v := m.search
+	switch msg.String() {
+	case "ctrl+c":
+		return m.quit()
+
+	case "esc", "q":
+		m.search = nil
+
+	case "j", "down":
+		if v.cursor < len(v.hits)-1 {
+			v.cursor++
+		}
+
+	case "k", "up":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+
+	case "/":
+		m.search = nil
+		m.openSearch()
+
+	case "enter":
+		m.search = nil
+		m.jumpTo(v.hits[v.cursor])
+	}
+	return m, nil
+}
+
+// jumpTo selects the hit's session, reopening it from the archive if it is
+// no longer open, and scrolls the output pane to the matching line.
+func (m *Model) jumpTo(hit store.Hit) {
+	sess := m.sessionManager.GetSession(hit.SessionID)
+	if sess == nil {
+		state, ok := m.store.Get(hit.SessionID)
+		if !ok {
+			m.notice = "Session " + hit.SessionID + " is gone"
+			return
+		}
+		sess = m.sessionManager.Reopen(state)
+		m.notice = "Reopened archived session " + sess.DisplayName()
+	}
+
+	m.selectedSession = sess
+	m.syncSelection()
+	m.focusedPane = OutputPane
+	m.highlight = &hit
+
+	// Show the match a few lines below the top of the pane for context.
+	m.outputScroll = hit.Line - 2
+	if maxScroll := m.maxOutputScroll(); m.outputScroll > maxScroll {
+		m.outputScroll = maxScroll
+	}
+	if m.outputScroll < 0 {
+		m.outputScroll = 0
+	}
+	m.followOutput = false
+}
+
+func (m *Model) renderSearch(height int) string {
+	v := m.search
+	visible := height - 3
+	start := 0
+	if v.cursor >= visible {
+		start = v.cursor - visible + 1
+	}
+
+	var lines []string
+	for i := start; i < len(v.hits) && i < start+visible; i++ {
+		hit := v.hits[i]
+		name := hit.Name
+		if m.sessionManager.GetSession(hit.SessionID) == nil {
+			name += " (archived)"
+		}
+		line := fmt.Sprintf("%s:%d  %s", name, hit.Line+1, strings.TrimSpace(hit.Text))
+		if i == v.cursor {
+			line = m.styles.SearchMatch.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"claude-session-manager/internal/config"
+	"claude-session-manager/internal/session"
+)
+
+// saveSnapshot writes all sessions to a timestamped file in the data
+// directory.
+func (m *Model) saveSnapshot() {
+	path, err := config.DataPath("snapshots", time.Now().Format("20060102-150405")+".json")
+	if err != nil {
+		m.notice = "Snapshot failed: " + err.Error()
+		return
+	}
+	if err := m.sessionManager.SaveSnapshot(path); err != nil {
+		m.notice = "Snapshot failed: " + err.Error()
+		return
+	}
+	m.notice = "Snapshot saved to " + path
+}
+
+// loadLatestSnapshot restores the newest snapshot in the data directory next
+// to the sessions already open.
+func (m *Model) loadLatestSnapshot() {
+	path, err := latestSnapshot()
+	if err != nil {
+		m.notice = "Snapshot load failed: " + err.Error()
+		return
+	}
+	snapshot, err := session.LoadSnapshot(path)
+	if err != nil {
+		m.notice = "Snapshot load failed: " + err.Error()
+		return
+	}
+	restored := m.sessionManager.Restore(snapshot)
+	m.notice = fmt.Sprintf("Restored %d sessions from %s", len(restored), filepath.Base(path))
+}
+
+func latestSnapshot() (string, error) {
+	dir, err := config.DataPath("snapshots", "")
+	if err != nil {
+		return "", err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no snapshots in %s", dir)
+	}
+	sort.Strings(names)
+	return filepath.Join(dir, names[len(names)-1]), nil
+}
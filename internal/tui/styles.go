@@ -18,9 +18,10 @@ type Styles struct {
 	SessionStopped  lipgloss.Style
 
 	// Output styles
-	OutputText lipgloss.Style
-	ErrorText  lipgloss.Style
-	InfoText   lipgloss.Style
+	OutputText  lipgloss.Style
+	ErrorText   lipgloss.Style
+	InfoText    lipgloss.Style
+	SearchMatch lipgloss.Style
 
 	// Input styles
 	InputField  lipgloss.Style
@@ -117,6 +118,10 @@ func NewStyles() *Styles {
 			Foreground(secondary).
 			Italic(true),
 
+		SearchMatch: lipgloss.NewStyle().
+			Foreground(surface).
+			Background(warning),
+
 		// Input styles
 		InputField: lipgloss.NewStyle().
 			Foreground(text).
@@ -0,0 +1,220 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"claude-session-manager/internal/templates"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// templateView lists the prompt templates in place of the output pane and
+// then asks for the chosen one's variables in a form. With forSession the
+// prompt starts a new session, and a nil entry at the top of the list stands
+// for a blank one; otherwise it is inserted into the input pane.
+type templateView struct {
+	list       []*templates.Template
+	cursor     int
+	forSession bool
+	form       *templateForm
+}
+
+// templateForm holds one value per variable of tmpl; field is the one
+// being edited.
+type templateForm struct {
+	tmpl   *templates.Template
+	values []string
+	field  int
+}
+
+// SetTemplateDir enables the prompt templates in dir.
+func (m *Model) SetTemplateDir(dir string) {
+	m.templateDir = dir
+}
+
+// openTemplates shows the template list, reporting whether there was
+// anything to show.
+func (m *Model) openTemplates(forSession bool) bool {
+	if m.templateDir == "" {
+		return false
+	}
+	list, err := templates.LoadDir(m.templateDir)
+	if err != nil {
+		m.notice = err.Error()
+	}
+	if len(list) == 0 {
+		return false
+	}
+	if forSession {
+		list = append([]*templates.Template{nil}, list...)
+	}
+	m.picker = &templateView{list: list, forSession: forSession}
+	return true
+}
+
+func (m *Model) handleTemplateKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
+	v := m.picker
+	if msg.String() == "ctrl+c" {
+		return m.quit()
+	}
+	if v.form != nil {
+		return m.handleTemplateFormKeys(msg)
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		m.picker = nil
+
+	case "j", "down":
+		if v.cursor < len(v.list)-1 {
+			v.cursor++
+		}
+
+	case "k", "up":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+
+	case "enter":
+		t := v.list[v.cursor]
+		if t == nil {
+			m.picker = nil
+			m.newSession("")
+			return m, nil
+		}
+		v.form = &templateForm{tmpl: t, values: make([]string, len(t.Variables))}
+		for i, variable := range t.Variables {
+			v.form.values[i] = variable.Default
+		}
+		if len(t.Variables) == 0 {
+			return m, m.submitTemplateForm()
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) handleTemplateFormKeys(msg tea.KeyMsg) (*Model, tea.Cmd) {
+	f := m.picker.form
+	switch msg.String() {
+	case "esc":
+		m.picker.form = nil
+
+	case "tab", "down":
+		f.field = (f.field + 1) % len(f.values)
+
+	case "shift+tab", "up":
+		f.field = (f.field + len(f.values) - 1) % len(f.values)
+
+	case "enter":
+		if f.field < len(f.values)-1 {
+			f.field++
+			return m, nil
+		}
+		return m, m.submitTemplateForm()
+
+	case "backspace":
+		if runes := []rune(f.values[f.field]); len(runes) > 0 {
+			f.values[f.field] = string(runes[:len(runes)-1])
+		}
+
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			f.values[f.field] += string(msg.Runes)
+		}
+	}
+	return m, nil
+}
+
+// submitTemplateForm renders the template from the form and uses the
+// prompt. A required variable left empty keeps the form open on it.
+func (m *Model) submitTemplateForm() tea.Cmd {
+	v := m.picker
+	f := v.form
+	values := make(map[string]string, len(f.values))
+	for i, variable := range f.tmpl.Variables {
+		if variable.Required && f.values[i] == "" {
+			f.field = i
+			m.notice = variable.Name + " is required"
+			return nil
+		}
+		values[variable.Name] = f.values[i]
+	}
+	prompt, err := f.tmpl.Render(values)
+	if err != nil {
+		m.notice = err.Error()
+		if len(f.values) == 0 {
+			v.form = nil
+		}
+		return nil
+	}
+	m.picker = nil
+
+	if !v.forSession {
+		m.inputValue += prompt
+		m.focusedPane = InputPane
+		return nil
+	}
+	return sendInputCmd(m.newSession(f.tmpl.Name), prompt)
+}
+
+func (m *Model) renderTemplates(height int) string {
+	v := m.picker
+	var lines []string
+	if f := v.form; f != nil {
+		for i, variable := range f.tmpl.Variables {
+			label := variable.Name
+			if variable.Required {
+				label += "*"
+			}
+			value := f.values[i]
+			if i == f.field {
+				lines = append(lines, m.styles.InputPrompt.Render(label+": ")+m.styles.InputField.Render(value+"█"))
+			} else {
+				lines = append(lines, label+": "+value)
+			}
+			if variable.Description != "" {
+				lines = append(lines, m.styles.InfoText.Render("  "+variable.Description))
+			}
+		}
+		lines = append(lines, "")
+		values := make(map[string]string, len(f.values))
+		for i, variable := range f.tmpl.Variables {
+			values[variable.Name] = f.values[i]
+		}
+		if prompt, err := f.tmpl.Render(values); err != nil {
+			lines = append(lines, m.styles.ErrorText.Render(err.Error()))
+		} else {
+			lines = append(lines, strings.Split(prompt, "\n")...)
+		}
+	} else {
+		for i, t := range v.list {
+			line := "Blank session"
+			if t != nil {
+				line = t.Name
+				if t.Description != "" {
+					line += " · " + t.Description
+				}
+			}
+			if i == v.cursor {
+				line = m.styles.SearchMatch.Render(line)
+			}
+			lines = append(lines, line)
+		}
+		if t := v.list[v.cursor]; t != nil {
+			lines = append(lines, "")
+			for _, variable := range t.Variables {
+				text := fmt.Sprintf("%s: %s", variable.Name, variable.Description)
+				if !variable.Required {
+					text += fmt.Sprintf(" (default %q)", variable.Default)
+				}
+				lines = append(lines, m.styles.InfoText.Render(text))
+			}
+			lines = append(lines, strings.Split(t.Body, "\n")...)
+		}
+	}
+
+	if visible := height - 3; len(lines) > visible && visible > 0 {
+		lines = lines[:visible]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	"claude-session-manager/internal/session"
+	"claude-session-manager/internal/workflow"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// workflowRun is a workflow started from the TUI; reported is set once its
+// outcome has been shown.
+type workflowRun struct {
+	run      *workflow.Run
+	reported bool
+}
+
+// NewWorkflowModel builds the TUI for a run of wf alone, without the demo
+// sessions or the offer to recover journaled ones.
+func NewWorkflowModel(sessionManager *session.Manager, wf *workflow.Workflow) *Model {
+	model := newModel(sessionManager)
+	model.StartWorkflow(wf)
+	return model
+}
+
+// StartWorkflow creates the workflow's sessions and starts running it,
+// selecting its first session.
+func (m *Model) StartWorkflow(wf *workflow.Workflow) {
+	run := workflow.Start(context.Background(), m.sessionManager, wf, nil)
+	m.workflows = append(m.workflows, &workflowRun{run: run})
+
+	if statuses := run.Statuses(); len(statuses) > 0 {
+		m.selectedSession = statuses[0].Session
+		m.syncSelection()
+		m.scrollOutputToBottom()
+	}
+	m.notice = fmt.Sprintf("Running workflow %s with %d sessions", workflowName(wf), len(wf.Nodes))
+}
+
+func (m *Model) promptWorkflow() {
+	m.openPrompt("Workflow file", "", func(path string) tea.Cmd {
+		if path == "" {
+			return nil
+		}
+		wf, err := workflow.Load(path)
+		if err != nil {
+			m.notice = err.Error()
+			return nil
+		}
+		m.StartWorkflow(wf)
+		return nil
+	})
+}
+
+// checkWorkflows reports workflows that have finished since the last check.
+func (m *Model) checkWorkflows() {
+	for _, w := range m.workflows {
+		if w.reported {
+			continue
+		}
+		select {
+		case <-w.run.Done():
+		default:
+			continue
+		}
+		w.reported = true
+		if err := w.run.Wait(); err != nil {
+			m.notice = fmt.Sprintf("Workflow %s failed: %v", workflowName(w.run.Workflow), err)
+		} else {
+			m.notice = fmt.Sprintf("Workflow %s finished", workflowName(w.run.Workflow))
+		}
+	}
+}
+
+// workflowBadge shows how far a workflow session has got.
+func (m *Model) workflowBadge(sess *session.Session) string {
+	for _, w := range m.workflows {
+		status, ok := w.run.SessionStatus(sess.ID)
+		if !ok {
+			continue
+		}
+		text := "◆ " + status.State.String()
+		switch status.State {
+		case workflow.NodeRunning:
+			return m.styles.StatusRunning.Render(text)
+		case workflow.NodeDone:
+			return m.styles.StatusIdle.Render(text)
+		case workflow.NodeFailed:
+			return m.styles.StatusError.Render(text)
+		default:
+			return m.styles.StatusStopped.Render(text)
+		}
+	}
+	return ""
+}
+
+func workflowName(wf *workflow.Workflow) string {
+	if wf.Name == "" {
+		return "(unnamed)"
+	}
+	return wf.Name
+}
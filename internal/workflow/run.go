@@ -0,0 +1,290 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"claude-session-manager/internal/session"
+)
+
+type NodeState int
+
+const (
+	NodeWaiting NodeState = iota
+	NodeRunning
+	NodeDone
+	NodeFailed
+	NodeSkipped
+)
+
+func (s NodeState) String() string {
+	switch s {
+	case NodeWaiting:
+		return "waiting"
+	case NodeRunning:
+		return "running"
+	case NodeDone:
+		return "done"
+	case NodeFailed:
+		return "failed"
+	case NodeSkipped:
+		return "skipped"
+	default:
+		return "unknown"
+	}
+}
+
+// NodeStatus is where one node of a run has got to. Detail explains a
+// failure or skip.
+type NodeStatus struct {
+	ID      string
+	Session *session.Session
+	State   NodeState
+	Detail  string
+}
+
+// Output is the last reply of a node nothing else consumes.
+type Output struct {
+	ID   string
+	Text string
+}
+
+// Run is one execution of a workflow. Every node runs at most once, as soon
+// as the nodes feeding it have finished.
+type Run struct {
+	Workflow *Workflow
+
+	onUpdate func(NodeStatus)
+	nodes    map[string]*NodeStatus
+	done     map[string]chan struct{}
+	finished chan struct{}
+	err      error
+	mu       sync.Mutex
+}
+
+// Start creates a session in m for every node and runs the graph in the
+// background. onUpdate, if set, is called whenever a node changes state.
+// Each session gets its node's ID as alias when that alias is free.
+func Start(ctx context.Context, m *session.Manager, wf *Workflow, onUpdate func(NodeStatus)) *Run {
+	r := &Run{
+		Workflow: wf,
+		onUpdate: onUpdate,
+		nodes:    make(map[string]*NodeStatus),
+		done:     make(map[string]chan struct{}),
+		finished: make(chan struct{}),
+	}
+
+	base := m.DefaultConfig()
+	for _, node := range wf.Nodes {
+		name := node.Name
+		if wf.Name != "" {
+			name = wf.Name + ": " + name
+		}
+		sess := m.CreateSession(name, node.config(base))
+		_ = m.SetAlias(sess.ID, node.ID)
+		r.nodes[node.ID] = &NodeStatus{ID: node.ID, Session: sess}
+		r.done[node.ID] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
+	for _, node := range wf.Nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.runNode(ctx, node)
+		}()
+	}
+	go func() {
+		wg.Wait()
+		r.finish(ctx)
+	}()
+	return r
+}
+
+func (r *Run) runNode(ctx context.Context, node Node) {
+	defer close(r.done[node.ID])
+
+	var (
+		inputs  []string
+		reasons []string
+	)
+	edges := r.Workflow.incoming(node.ID)
+	for _, edge := range edges {
+		for _, from := range edge.From {
+			select {
+			case <-r.done[from]:
+			case <-ctx.Done():
+				r.set(node.ID, NodeSkipped, "cancelled")
+				return
+			}
+		}
+		input, reason, err := r.fire(edge)
+		if err != nil {
+			r.set(node.ID, NodeFailed, err.Error())
+			return
+		}
+		if reason != "" {
+			reasons = append(reasons, reason)
+			continue
+		}
+		inputs = append(inputs, input)
+	}
+	if len(edges) > 0 && len(inputs) == 0 {
+		r.set(node.ID, NodeSkipped, strings.Join(reasons, "; "))
+		return
+	}
+
+	parts := inputs
+	if prompt := strings.TrimSpace(node.Prompt); prompt != "" {
+		parts = append([]string{prompt}, inputs...)
+	}
+	r.set(node.ID, NodeRunning, "")
+	if err := r.nodes[node.ID].Session.Send(ctx, strings.Join(parts, "\n\n"), nil); err != nil {
+		r.set(node.ID, NodeFailed, err.Error())
+		return
+	}
+	r.set(node.ID, NodeDone, "")
+}
+
+// fire works out what an edge sends once its sources have finished. When
+// it does not fire, reason says why.
+func (r *Run) fire(edge Edge) (input, reason string, err error) {
+	var (
+		outputs []string
+		names   []string
+	)
+	for _, from := range edge.From {
+		status := r.Status(from)
+		if status.State != NodeDone {
+			return "", fmt.Sprintf("%s %s", from, status.State), nil
+		}
+		reply, err := status.Session.Select(session.Selection{Kind: session.SelectLastReply})
+		if err != nil {
+			return "", "", err
+		}
+		if len(edge.From) > 1 {
+			reply = fmt.Sprintf("%s:\n%s", from, reply)
+		}
+		outputs = append(outputs, reply)
+		names = append(names, status.Session.DisplayName())
+	}
+
+	output := strings.Join(outputs, "\n\n")
+	if edge.When != nil && !edge.When.MatchString(output) {
+		return "", fmt.Sprintf("%s did not match /%s/", strings.Join(edge.From, ", "), edge.When), nil
+	}
+	if edge.tmpl == nil {
+		return output, "", nil
+	}
+
+	var b strings.Builder
+	data := session.PipeData{Output: output, Source: strings.Join(names, ", "), SourceID: strings.Join(edge.From, ", ")}
+	if err := edge.tmpl.Execute(&b, data); err != nil {
+		return "", "", err
+	}
+	return b.String(), "", nil
+}
+
+func (r *Run) set(id string, state NodeState, detail string) {
+	r.mu.Lock()
+	status := r.nodes[id]
+	status.State, status.Detail = state, detail
+	update := *status
+	r.mu.Unlock()
+
+	if r.onUpdate != nil {
+		r.onUpdate(update)
+	}
+}
+
+func (r *Run) finish(ctx context.Context) {
+	var failed []string
+	for _, status := range r.Statuses() {
+		if status.State == NodeFailed {
+			failed = append(failed, status.ID)
+		}
+	}
+
+	r.mu.Lock()
+	switch {
+	case len(failed) > 0:
+		r.err = fmt.Errorf("%d of %d sessions failed: %s", len(failed), len(r.Workflow.Nodes), strings.Join(failed, ", "))
+	case ctx.Err() != nil:
+		r.err = ctx.Err()
+	}
+	r.mu.Unlock()
+	close(r.finished)
+}
+
+// Done is closed once every node has finished, failed or been skipped.
+func (r *Run) Done() <-chan struct{} {
+	return r.finished
+}
+
+// Wait blocks until the run is over and returns why it failed, if it did.
+func (r *Run) Wait() error {
+	<-r.finished
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Status returns the state of the node with the given ID.
+func (r *Run) Status(id string) NodeStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.nodes[id]
+}
+
+// Statuses returns every node's state, in the order the file lists them.
+func (r *Run) Statuses() []NodeStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	statuses := make([]NodeStatus, 0, len(r.Workflow.Nodes))
+	for _, node := range r.Workflow.Nodes {
+		statuses = append(statuses, *r.nodes[node.ID])
+	}
+	return statuses
+}
+
+// SessionStatus finds the node a session belongs to.
+func (r *Run) SessionStatus(sessionID string) (NodeStatus, bool) {
+	for _, status := range r.Statuses() {
+		if status.Session.ID == sessionID {
+			return status, true
+		}
+	}
+	return NodeStatus{}, false
+}
+
+// Outputs returns the last replies of the finished nodes that no other
+// finished node took its input from, which are the results of the workflow.
+func (r *Run) Outputs() []Output {
+	statuses := r.Statuses()
+	consumed := make(map[string]bool)
+	for _, status := range statuses {
+		if status.State != NodeDone {
+			continue
+		}
+		for _, edge := range r.Workflow.incoming(status.ID) {
+			for _, from := range edge.From {
+				consumed[from] = true
+			}
+		}
+	}
+
+	var outputs []Output
+	for _, status := range statuses {
+		if status.State != NodeDone || consumed[status.ID] {
+			continue
+		}
+		reply, err := status.Session.Select(session.Selection{Kind: session.SelectLastReply})
+		if err == nil {
+			outputs = append(outputs, Output{ID: status.ID, Text: reply})
+		}
+	}
+	return outputs
+}
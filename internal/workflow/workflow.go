@@ -0,0 +1,304 @@
+// Package workflow runs declarative graphs of sessions: each node is a
+// session with an optional first prompt, and edges pipe the replies of
+// finished nodes into the nodes after them.
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"claude-session-manager/internal/session"
+	"claude-session-manager/internal/yaml"
+)
+
+// Workflow is a parsed workflow file.
+//
+//	name: review
+//	sessions:
+//	  - id: draft
+//	    prompt: Write a haiku about Go.
+//	  - id: critic
+//	    system: You are a harsh critic.
+//	edges:
+//	  - from: draft
+//	    to: critic
+//	    template: "Critique this:\n\n{{.Output}}"
+type Workflow struct {
+	Name  string
+	Nodes []Node
+	Edges []Edge
+}
+
+// Node is one session of a workflow. Fields left empty fall back to the
+// manager's default session config.
+type Node struct {
+	ID           string
+	Name         string
+	Prompt       string
+	Model        string
+	SystemPrompt string
+	MaxTokens    int
+	Temperature  *float64
+	Scratchpads  []string
+}
+
+// Edge sends the last replies of From to each of To once all of From have
+// finished. Several sources fan in: their replies are joined, each labelled
+// with its node's ID. When is an optional regular expression the joined reply
+// must match for the edge to fire.
+type Edge struct {
+	From     []string
+	To       []string
+	When     *regexp.Regexp
+	Template string
+
+	tmpl *template.Template
+}
+
+// Load reads and validates a workflow file.
+func Load(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	wf, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return wf, nil
+}
+
+// Parse reads and validates a workflow from YAML.
+func Parse(data string) (*Workflow, error) {
+	var doc file
+	if err := yaml.Decode(data, &doc); err != nil {
+		return nil, err
+	}
+	wf, err := decodeWorkflow(doc)
+	if err != nil {
+		return nil, err
+	}
+	if err := wf.validate(); err != nil {
+		return nil, err
+	}
+	return wf, nil
+}
+
+// Node returns the node with the given ID.
+func (wf *Workflow) Node(id string) (Node, bool) {
+	for _, node := range wf.Nodes {
+		if node.ID == id {
+			return node, true
+		}
+	}
+	return Node{}, false
+}
+
+// incoming returns the edges into a node.
+func (wf *Workflow) incoming(id string) []Edge {
+	var edges []Edge
+	for _, edge := range wf.Edges {
+		if slices.Contains(edge.To, id) {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+func (wf *Workflow) validate() error {
+	if len(wf.Nodes) == 0 {
+		return fmt.Errorf("a workflow needs at least one session")
+	}
+	seen := make(map[string]bool)
+	for _, node := range wf.Nodes {
+		if node.ID == "" {
+			return fmt.Errorf("every session needs an id")
+		}
+		if strings.ContainsAny(node.ID, " \t\n|") {
+			return fmt.Errorf("session id %q must not contain spaces or '|'", node.ID)
+		}
+		if seen[node.ID] {
+			return fmt.Errorf("session id %q is used twice", node.ID)
+		}
+		seen[node.ID] = true
+	}
+
+	for i, edge := range wf.Edges {
+		if len(edge.From) == 0 || len(edge.To) == 0 {
+			return fmt.Errorf("edges[%d]: needs both from and to", i)
+		}
+		for _, id := range append(slices.Clone(edge.From), edge.To...) {
+			if !seen[id] {
+				return fmt.Errorf("edges[%d]: unknown session %q", i, id)
+			}
+		}
+		for _, id := range edge.To {
+			if slices.Contains(edge.From, id) {
+				return fmt.Errorf("edges[%d]: %s cannot feed itself", i, id)
+			}
+		}
+	}
+
+	for _, node := range wf.Nodes {
+		if len(wf.incoming(node.ID)) == 0 && strings.TrimSpace(node.Prompt) == "" {
+			return fmt.Errorf("session %q has no prompt and no edge into it", node.ID)
+		}
+	}
+	if cycle := wf.findCycle(); cycle != nil {
+		return fmt.Errorf("edges form a cycle: %s", strings.Join(cycle, " → "))
+	}
+	return nil
+}
+
+// findCycle returns the nodes of a cycle in the graph, if there is one.
+func (wf *Workflow) findCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		state[id] = visiting
+		path = append(path, id)
+		for _, edge := range wf.Edges {
+			if !slices.Contains(edge.From, id) {
+				continue
+			}
+			for _, next := range edge.To {
+				switch state[next] {
+				case visiting:
+					start := slices.Index(path, next)
+					return append(slices.Clone(path[start:]), next)
+				case unvisited:
+					if cycle := visit(next); cycle != nil {
+						return cycle
+					}
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		return nil
+	}
+	for _, node := range wf.Nodes {
+		if state[node.ID] == unvisited {
+			if cycle := visit(node.ID); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// config builds a node's session config over base.
+func (node Node) config(base session.SessionConfig) session.SessionConfig {
+	config := base
+	if node.Model != "" {
+		config.Model = node.Model
+	}
+	if node.SystemPrompt != "" {
+		config.SystemPrompt = node.SystemPrompt
+	}
+	if node.MaxTokens > 0 {
+		config.MaxTokens = node.MaxTokens
+	}
+	if node.Temperature != nil {
+		config.Temperature = node.Temperature
+	}
+	if len(node.Scratchpads) > 0 {
+		config.Scratchpads = slices.Clone(node.Scratchpads)
+	}
+	return config
+}
+
+// file is the layout of a workflow file.
+type file struct {
+	Name     string     `yaml:"name"`
+	Sessions []nodeFile `yaml:"sessions"`
+	Edges    []edgeFile `yaml:"edges"`
+}
+
+type nodeFile struct {
+	ID          string    `yaml:"id"`
+	Name        string    `yaml:"name"`
+	Prompt      string    `yaml:"prompt"`
+	Model       string    `yaml:"model"`
+	System      string    `yaml:"system"`
+	MaxTokens   *int      `yaml:"max_tokens"`
+	Temperature *float64  `yaml:"temperature"`
+	Scratchpads yaml.List `yaml:"scratchpads"`
+}
+
+type edgeFile struct {
+	From     yaml.List `yaml:"from"`
+	To       yaml.List `yaml:"to"`
+	When     string    `yaml:"when"`
+	Template string    `yaml:"template"`
+}
+
+func decodeWorkflow(doc file) (*Workflow, error) {
+	wf := &Workflow{Name: doc.Name}
+	for i, item := range doc.Sessions {
+		node, err := decodeNode(item, fmt.Sprintf("sessions[%d]", i))
+		if err != nil {
+			return nil, err
+		}
+		wf.Nodes = append(wf.Nodes, node)
+	}
+	for i, item := range doc.Edges {
+		edge, err := decodeEdge(item, fmt.Sprintf("edges[%d]", i))
+		if err != nil {
+			return nil, err
+		}
+		wf.Edges = append(wf.Edges, edge)
+	}
+	return wf, nil
+}
+
+func decodeNode(item nodeFile, path string) (Node, error) {
+	node := Node{
+		ID:           item.ID,
+		Name:         item.Name,
+		Prompt:       item.Prompt,
+		Model:        item.Model,
+		SystemPrompt: item.System,
+		Temperature:  item.Temperature,
+		Scratchpads:  item.Scratchpads,
+	}
+	if node.Name == "" {
+		node.Name = node.ID
+	}
+	if item.MaxTokens != nil {
+		if *item.MaxTokens < 1 {
+			return Node{}, fmt.Errorf("%s.max_tokens: want a positive integer, got %d", path, *item.MaxTokens)
+		}
+		node.MaxTokens = *item.MaxTokens
+	}
+	if item.Temperature != nil && *item.Temperature < 0 {
+		return Node{}, fmt.Errorf("%s.temperature: want a non-negative number, got %g", path, *item.Temperature)
+	}
+	return node, nil
+}
+
+func decodeEdge(item edgeFile, path string) (Edge, error) {
+	edge := Edge{From: item.From, To: item.To, Template: item.Template}
+	var err error
+	if item.When != "" {
+		if edge.When, err = regexp.Compile(item.When); err != nil {
+			return Edge{}, fmt.Errorf("%s.when: %w", path, err)
+		}
+	}
+	if edge.Template != "" {
+		if edge.tmpl, err = template.New(path).Option("missingkey=error").Parse(edge.Template); err != nil {
+			return Edge{}, fmt.Errorf("%s.template: %w", path, err)
+		}
+	}
+	return edge, nil
+}
@@ -0,0 +1,298 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"claude-session-manager/internal/session"
+)
+
+func TestParseValidates(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name:    "no sessions",
+			data:    "name: empty\n",
+			wantErr: "at least one session",
+		},
+		{
+			name:    "missing id",
+			data:    "sessions:\n  - prompt: hi\n",
+			wantErr: "every session needs an id",
+		},
+		{
+			name:    "id with a pipe",
+			data:    "sessions:\n  - id: a|b\n    prompt: hi\n",
+			wantErr: `session id "a|b" must not contain`,
+		},
+		{
+			name:    "duplicate id",
+			data:    "sessions:\n  - id: a\n    prompt: hi\n  - id: a\n    prompt: hi\n",
+			wantErr: `session id "a" is used twice`,
+		},
+		{
+			name:    "unknown session",
+			data:    "sessions:\n  - id: a\n    prompt: hi\nedges:\n  - from: a\n    to: b\n",
+			wantErr: `edges[0]: unknown session "b"`,
+		},
+		{
+			name:    "edge without target",
+			data:    "sessions:\n  - id: a\n    prompt: hi\nedges:\n  - from: a\n",
+			wantErr: "edges[0]: needs both from and to",
+		},
+		{
+			name:    "feeds itself",
+			data:    "sessions:\n  - id: a\n    prompt: hi\n  - id: b\n    prompt: hi\nedges:\n  - from: [a, b]\n    to: a\n",
+			wantErr: "edges[0]: a cannot feed itself",
+		},
+		{
+			name:    "nothing to send",
+			data:    "sessions:\n  - id: a\n",
+			wantErr: `session "a" has no prompt and no edge into it`,
+		},
+		{
+			name:    "cycle",
+			data:    "sessions:\n  - id: a\n    prompt: hi\n  - id: b\n  - id: c\nedges:\n  - from: a\n    to: b\n  - from: b\n    to: c\n  - from: c\n    to: b\n",
+			wantErr: "edges form a cycle: b → c → b",
+		},
+		{
+			name:    "bad when",
+			data:    "sessions:\n  - id: a\n    prompt: hi\n  - id: b\nedges:\n  - from: a\n    to: b\n    when: \"(\"\n",
+			wantErr: "edges[0].when:",
+		},
+		{
+			name:    "max_tokens not a number",
+			data:    "sessions:\n  - id: a\n    prompt: hi\n    max_tokens: lots\n",
+			wantErr: "line 4: cannot unmarshal !!str `lots` into int",
+		},
+		{
+			name:    "max_tokens not positive",
+			data:    "sessions:\n  - id: a\n    prompt: hi\n    max_tokens: 0\n",
+			wantErr: "sessions[0].max_tokens: want a positive integer, got 0",
+		},
+		{
+			name:    "negative temperature",
+			data:    "sessions:\n  - id: a\n    prompt: hi\n    temperature: -1\n",
+			wantErr: "sessions[0].temperature: want a non-negative number, got -1",
+		},
+		{
+			name:    "unknown field",
+			data:    "sessions:\n  - id: a\n    promt: hi\n",
+			wantErr: "line 3: field promt not found",
+		},
+		{
+			name:    "unknown top-level field",
+			data:    "defaults:\n  model: opus\nsessions:\n  - id: a\n    prompt: hi\n",
+			wantErr: "line 1: field defaults not found",
+		},
+		{
+			name:    "list for a string",
+			data:    "sessions:\n  - id: [a, b]\n    prompt: hi\n",
+			wantErr: "line 2: cannot unmarshal !!seq into string",
+		},
+		{
+			name:    "unknown alias",
+			data:    "sessions:\n  - id: a\n    prompt: *missing\n",
+			wantErr: `unknown anchor 'missing'`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	// Anchors, merge keys, flow mappings and escapes mean what YAML says.
+	wf, err := Parse(`
+name: "review \u2713"
+sessions:
+  - {id: draft, prompt: &ask "Write a haiku.", scratchpads: notes}
+  - &critic
+    id: critic
+    model: opus
+    max_tokens: 300
+    system: |
+      Be harsh.
+    temperature: 0.5
+  - <<: *critic
+    id: again
+    prompt: *ask
+    scratchpads: [notes, style]
+edges:
+  - {from: draft, to: critic}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wf.Name != "review ✓" {
+		t.Errorf("name = %q", wf.Name)
+	}
+	draft, _ := wf.Node("draft")
+	if draft.Prompt != "Write a haiku." || !slices.Equal(draft.Scratchpads, []string{"notes"}) {
+		t.Errorf("draft = %+v", draft)
+	}
+	critic, _ := wf.Node("critic")
+	if critic.Model != "opus" || critic.MaxTokens != 300 || critic.SystemPrompt != "Be harsh.\n" || critic.Temperature == nil || *critic.Temperature != 0.5 {
+		t.Errorf("critic = %+v", critic)
+	}
+	again, _ := wf.Node("again")
+	if again.Prompt != "Write a haiku." || again.Model != "opus" || again.MaxTokens != 300 || !slices.Equal(again.Scratchpads, []string{"notes", "style"}) {
+		t.Errorf("again = %+v", again)
+	}
+	if len(wf.Edges) != 1 || !slices.Equal(wf.Edges[0].From, []string{"draft"}) || !slices.Equal(wf.Edges[0].To, []string{"critic"}) {
+		t.Errorf("edges = %+v", wf.Edges)
+	}
+}
+
+func TestFindCycle(t *testing.T) {
+	nodes := []Node{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  []string
+	}{
+		{
+			name: "diamond",
+			edges: []Edge{
+				{From: []string{"a"}, To: []string{"b", "c"}},
+				{From: []string{"b", "c"}, To: []string{"d"}},
+			},
+		},
+		{
+			name: "loop through a fan-in",
+			edges: []Edge{
+				{From: []string{"a", "d"}, To: []string{"b"}},
+				{From: []string{"b"}, To: []string{"c"}},
+				{From: []string{"c"}, To: []string{"d"}},
+			},
+			want: []string{"b", "c", "d", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wf := &Workflow{Nodes: nodes, Edges: tt.edges}
+			if got := wf.findCycle(); !slices.Equal(got, tt.want) {
+				t.Errorf("findCycle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// failingBackend fails every request without a retry.
+type failingBackend struct{}
+
+func (failingBackend) Send(ctx context.Context, req session.Request, emit func(session.Chunk)) error {
+	return errors.New("invalid request")
+}
+func (failingBackend) Cancel()                            {}
+func (failingBackend) Close() error                       { return nil }
+func (failingBackend) Capabilities() session.Capabilities { return session.Capabilities{} }
+
+func startRun(t *testing.T, data string) *Run {
+	t.Helper()
+	wf, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := session.NewManager()
+	m.SetBackendFactory(func(s *session.Session) session.Backend {
+		if s.Name == "broken" {
+			return failingBackend{}
+		}
+		return session.NewFakeBackend()
+	})
+	return Start(context.Background(), m, wf, nil)
+}
+
+func TestRunFanInAndWhen(t *testing.T) {
+	run := startRun(t, `
+sessions:
+  - id: security
+    prompt: Check security.
+  - id: perf
+    prompt: Check speed.
+  - id: editor
+    prompt: Merge the reviews.
+  - id: signoff
+  - id: after
+edges:
+  - from: [security, perf]
+    to: editor
+  - from: editor
+    to: signoff
+    when: "(?i)approved"
+  - from: signoff
+    to: after
+`)
+	if err := run.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	states := make(map[string]NodeState)
+	for _, status := range run.Statuses() {
+		states[status.ID] = status.State
+	}
+	want := map[string]NodeState{
+		"security": NodeDone,
+		"perf":     NodeDone,
+		"editor":   NodeDone,
+		"signoff":  NodeSkipped,
+		"after":    NodeSkipped,
+	}
+	for id, state := range want {
+		if states[id] != state {
+			t.Errorf("%s is %s, want %s", id, states[id], state)
+		}
+	}
+	if detail := run.Status("signoff").Detail; detail != "editor did not match /(?i)approved/" {
+		t.Errorf("signoff skipped because %q", detail)
+	}
+
+	// The editor waited for both reviews and got them labelled.
+	transcript := run.Status("editor").Session.GetTranscript()
+	got := transcript[0].Text()
+	wantPrompt := "Merge the reviews.\n\nsecurity:\nClaude response to: Check security.\n\nperf:\nClaude response to: Check speed."
+	if got != wantPrompt {
+		t.Errorf("editor prompt = %q, want %q", got, wantPrompt)
+	}
+
+	outputs := run.Outputs()
+	if len(outputs) != 1 || outputs[0].ID != "editor" {
+		t.Errorf("outputs = %+v, want the editor's reply", outputs)
+	}
+}
+
+func TestRunFails(t *testing.T) {
+	run := startRun(t, `
+sessions:
+  - id: broken
+    prompt: Go.
+  - id: fine
+    prompt: Go.
+  - id: next
+edges:
+  - from: broken
+    to: next
+`)
+	err := run.Wait()
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 sessions failed: broken") {
+		t.Fatalf("err = %v, want the broken session to fail the run", err)
+	}
+	if status := run.Status("next"); status.State != NodeSkipped || status.Detail != "broken failed" {
+		t.Errorf("next is %s (%s), want skipped because broken failed", status.State, status.Detail)
+	}
+	if status := run.Status("fine"); status.State != NodeDone {
+		t.Errorf("fine is %s, want done", status.State)
+	}
+}
@@ -0,0 +1,44 @@
+// Package yaml reads ClaudePilot's YAML files.
+package yaml
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Decode reads the YAML document in data into v with gopkg.in/yaml.v3. Keys
+// v has no field for are an error, and an empty document leaves v as it is.
+func Decode(data string, v any) error {
+	decoder := yaml.NewDecoder(strings.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// List is a list of strings that may also be written as a single string.
+type List []string
+
+func (l *List) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var text string
+		if err := node.Decode(&text); err != nil {
+			return err
+		}
+		*l = nil
+		if text != "" {
+			*l = List{text}
+		}
+		return nil
+	}
+	var items []string
+	if err := node.Decode(&items); err != nil {
+		return err
+	}
+	*l = items
+	return nil
+}
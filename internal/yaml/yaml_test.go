@@ -0,0 +1,47 @@
+package yaml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	type doc struct {
+		Name string `yaml:"name"`
+		Tags List   `yaml:"tags"`
+	}
+	tests := []struct {
+		name    string
+		data    string
+		want    doc
+		wantErr string
+	}{
+		{name: "empty document", data: "# nothing\n", want: doc{}},
+		{name: "one tag", data: "name: a\ntags: go\n", want: doc{Name: "a", Tags: List{"go"}}},
+		{name: "tag list", data: "tags: [go, lua]\n", want: doc{Tags: List{"go", "lua"}}},
+		{name: "null tags", data: "tags: ~\n", want: doc{}},
+		{name: "alias", data: "name: &n x\ntags: *n\n", want: doc{Name: "x", Tags: List{"x"}}},
+		{name: "unknown key", data: "name: a\ncolour: red\n", wantErr: "line 2: field colour not found"},
+		{name: "mapping for tags", data: "tags: {a: b}\n", wantErr: "cannot unmarshal !!map into []string"},
+		{name: "bad syntax", data: "name: [a\n", wantErr: "did not find expected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got doc
+			err := Decode(tt.data, &got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}